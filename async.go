@@ -0,0 +1,68 @@
+package opencc
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Result is the outcome of converting one input received by ConvertAsync.
+type Result struct {
+	Index  int    // position of the input on the input channel
+	Output string // converted text, if Err is nil
+	Err    error
+}
+
+// ConvertAsync converts each string received from inputs using up to
+// GOMAXPROCS converters from the pool at once, and sends a Result for it on
+// the returned channel. Results arrive in the order conversions finish, so
+// use Result.Index to restore the input order. A failed conversion doesn't
+// stop the others. The returned channel is closed once inputs is closed and
+// every result has been sent, so the caller must keep receiving until then.
+func (p *ConverterPool) ConvertAsync(inputs <-chan string) <-chan Result {
+	type job struct {
+		index int
+		input string
+	}
+	jobs := make(chan job)
+	go func() {
+		defer close(jobs)
+
+		index := 0
+		for input := range inputs {
+			jobs <- job{index, input}
+			index++
+		}
+	}()
+
+	workers := runtime.GOMAXPROCS(0)
+	results := make(chan Result, workers)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var c *Converter
+			defer func() { p.Put(c) }()
+
+			for j := range jobs {
+				res := Result{Index: j.index}
+				if j.input != "" {
+					if c == nil {
+						c, res.Err = p.Get()
+					}
+					if res.Err == nil {
+						res.Output, res.Err = c.convertPart(j.input)
+					}
+				}
+				results <- res
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
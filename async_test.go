@@ -0,0 +1,71 @@
+package opencc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestConverterPoolConvertAsync(t *testing.T) {
+	pool := NewConverterPool("s2t.json")
+	defer pool.Close()
+
+	inputs := []string{"简体字", "", "汉字", "a\x00b", "这是一个测试"}
+	want := []string{"簡體字", "", "漢字", "", "這是一個測試"}
+
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for _, input := range inputs {
+			in <- input
+		}
+	}()
+
+	got := make([]string, len(inputs))
+	seen := make(map[int]bool)
+	for res := range pool.ConvertAsync(in) {
+		if seen[res.Index] {
+			t.Fatalf("duplicate result for input %d", res.Index)
+		}
+		seen[res.Index] = true
+
+		if res.Index == 3 {
+			if !errors.Is(res.Err, ErrInvalidInput) {
+				t.Errorf("result %d error = %v, want %v", res.Index, res.Err, ErrInvalidInput)
+			}
+			continue
+		}
+		if res.Err != nil {
+			t.Errorf("result %d error = %v", res.Index, res.Err)
+		}
+		got[res.Index] = res.Output
+	}
+
+	if len(seen) != len(inputs) {
+		t.Errorf("got %d results, want %d", len(seen), len(inputs))
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("results = %q, want %q", got, want)
+	}
+}
+
+func TestConverterPoolConvertAsyncClosed(t *testing.T) {
+	pool := NewConverterPool("s2t.json")
+	pool.Close()
+
+	in := make(chan string, 2)
+	in <- "简体字"
+	in <- "汉字"
+	close(in)
+
+	n := 0
+	for res := range pool.ConvertAsync(in) {
+		n++
+		if !errors.Is(res.Err, ErrInvalidConverter) {
+			t.Errorf("result %d error = %v, want %v", res.Index, res.Err, ErrInvalidConverter)
+		}
+	}
+	if n != 2 {
+		t.Errorf("got %d results, want 2", n)
+	}
+}
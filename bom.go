@@ -0,0 +1,46 @@
+package opencc
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// bom is the UTF-8 encoded byte order mark.
+const bom = "\ufeff"
+
+// cutBOM returns input without a leading byte order mark, and whether it
+// had one.
+func cutBOM(input any) (any, bool) {
+	switch v := input.(type) {
+	case string:
+		if s, ok := strings.CutPrefix(v, bom); ok {
+			return s, true
+		}
+	case []byte:
+		if b, ok := bytes.CutPrefix(v, []byte(bom)); ok {
+			return b, true
+		}
+	}
+	return input, false
+}
+
+// writeBOM starts the result in dest with a byte order mark, if dest is
+// written as the result is produced. It reports whether it succeeded.
+func writeBOM(dest any) bool {
+	if d, ok := dest.(*writerDest); ok {
+		d.n, d.err = io.WriteString(d.w, bom)
+		return d.err == nil
+	}
+	return true
+}
+
+// addBOM prefixes the result stored in dest with a byte order mark.
+func addBOM(dest any) {
+	switch d := dest.(type) {
+	case *string:
+		*d = bom + *d
+	case *[]byte:
+		*d = append([]byte(bom), *d...)
+	}
+}
@@ -0,0 +1,119 @@
+package opencc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBOM(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		input string
+		want  string
+	}{
+		{"no BOM", nil, "简体字", "簡體字"},
+		{"stripped", nil, "\ufeff简体字", "簡體字"},
+		{"only BOM", nil, "\ufeff", ""},
+		{"not leading", nil, "简体\ufeff字", "簡體\ufeff字"},
+		{"kept", []Option{WithKeepBOM()}, "\ufeff简体字", "\ufeff簡體字"},
+		{"kept without BOM", []Option{WithKeepBOM()}, "简体字", "簡體字"},
+		{"kept with whitespace", []Option{WithKeepBOM(), WithPreserveWhitespace()}, "\ufeff简体 字\n", "\ufeff簡體 字\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter("s2t.json", tt.opts...)
+			if err != nil {
+				t.Fatalf("NewConverter() error = %v", err)
+			}
+			defer converter.Close()
+
+			if got, err := converter.Convert(tt.input); err != nil || got != tt.want {
+				t.Errorf("Convert(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+			}
+			if got, err := converter.ConvertBytes([]byte(tt.input)); err != nil || string(got) != tt.want {
+				t.Errorf("ConvertBytes(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+			}
+
+			var buf bytes.Buffer
+			if n, err := converter.ConvertTo(&buf, tt.input); err != nil || n != len(tt.want) || buf.String() != tt.want {
+				t.Errorf("ConvertTo(%q) = %d, %v, wrote %q, want %q", tt.input, n, err, buf.String(), tt.want)
+			}
+
+			buf.Reset()
+			if err := converter.ConvertStream(strings.NewReader(tt.input), &buf); err != nil || buf.String() != tt.want {
+				t.Errorf("ConvertStream(%q) wrote %q, %v, want %q", tt.input, buf.String(), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBOMStreamChunks(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	// A line starting with U+FEFF at a chunk boundary keeps it: only the
+	// start of the stream holds a byte order mark
+	line := strings.Repeat("简", StreamChunkSize/3-1) + "\n"
+	line = line[len(line)%StreamChunkSize:]
+	first := strings.Repeat("a", StreamChunkSize-len(line)) + line
+	input := "\ufeff" + first[len("\ufeff"):] + "\ufeff简体字\n"
+	if len(input) < StreamChunkSize || !strings.HasPrefix(input[StreamChunkSize:], "\ufeff") {
+		t.Fatalf("second chunk doesn't start with U+FEFF")
+	}
+
+	var buf bytes.Buffer
+	if err := converter.ConvertStream(strings.NewReader(input), &buf); err != nil {
+		t.Fatalf("ConvertStream() error = %v", err)
+	}
+	got := buf.String()
+	if strings.HasPrefix(got, "\ufeff") {
+		t.Error("ConvertStream() kept the leading byte order mark")
+	}
+	if !strings.HasSuffix(got, "\ufeff簡體字\n") {
+		t.Errorf("ConvertStream() output ends with %q, want U+FEFF kept mid-stream", got[len(got)-20:])
+	}
+}
+
+func TestBOMInParts(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+	pool := NewConverterPool("s2t.json")
+	defer pool.Close()
+
+	// Lines, fields and batch items are pieces of a document, where U+FEFF
+	// is a zero width no-break space rather than a byte order mark
+	lines, err := pool.ConvertLines([]string{strings.Repeat("汉", 6000), "\ufeff汉", "\ufeff汉"}, 2)
+	if err != nil || lines[1] != "\ufeff漢" || lines[2] != "\ufeff漢" {
+		t.Errorf("ConvertLines() = %q, %v, want U+FEFF kept in every line", lines[1:], err)
+	}
+
+	batch, err := converter.ConvertBatch([]string{"\ufeff汉"})
+	if err != nil || batch[0] != "\ufeff漢" {
+		t.Errorf("ConvertBatch() = %q, %v, want %q", batch, err, []string{"\ufeff漢"})
+	}
+
+	parallel, err := pool.ConvertParallel([]string{"\ufeff汉"}, 1)
+	if err != nil || parallel[0] != "\ufeff漢" {
+		t.Errorf("ConvertParallel() = %q, %v, want %q", parallel, err, []string{"\ufeff漢"})
+	}
+
+	json, err := ConvertJSON(converter, []byte(`{"a":"\ufeff汉"}`))
+	if want := "{\"a\":\"\ufeff漢\"}"; err != nil || string(json) != want {
+		t.Errorf("ConvertJSON() = %q, %v, want %q", json, err, want)
+	}
+
+	var csv bytes.Buffer
+	err = ConvertCSV(converter, strings.NewReader("1,\ufeff汉\n"), &csv, []int{1})
+	if want := "1,\ufeff漢\n"; err != nil || csv.String() != want {
+		t.Errorf("ConvertCSV() = %q, %v, want %q", csv.String(), err, want)
+	}
+}
@@ -116,6 +116,10 @@ if [ -f "opencc.wasm" ]; then
         echo -e "${GREEN}All essential data files are present${NC}"
     fi
     
+    # Compress dictionaries; they are decompressed in memory at load
+    echo -e "${BLUE}Compressing dictionaries...${NC}"
+    gzip -9 -n -f data/*.ocd2
+
     # Show data directory contents
     echo -e "${BLUE}Data directory contents:${NC}"
     ls -la data/ | head -10
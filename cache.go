@@ -0,0 +1,115 @@
+package opencc
+
+import (
+	"container/list"
+	"sync"
+)
+
+// CachedConverter wraps a Conversion with an LRU cache of results, so
+// repeated inputs are converted without calling into WASM. It is safe for
+// concurrent use.
+//
+// The cache holds every distinct input it sees until it is evicted, so it
+// is meant for bounded, repetitive inputs such as labels and tags rather
+// than arbitrary documents.
+type CachedConverter struct {
+	c           Conversion
+	maxEntries  int
+	maxInputLen int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     list.List // of *cacheEntry, most recently used first
+}
+
+type cacheEntry struct {
+	input, output string
+}
+
+var _ Conversion = (*CachedConverter)(nil)
+
+// CacheOption configures a CachedConverter.
+type CacheOption func(*CachedConverter)
+
+// WithMaxInputLen stops inputs longer than n bytes from being cached.
+// Longer inputs are converted every time.
+func WithMaxInputLen(n int) CacheOption {
+	return func(cc *CachedConverter) {
+		cc.maxInputLen = n
+	}
+}
+
+// NewCachedConverter returns a CachedConverter keeping up to maxEntries
+// results of c, evicting the least recently used one when full. Closing it
+// closes c.
+func NewCachedConverter(c Conversion, maxEntries int, opts ...CacheOption) *CachedConverter {
+	cc := &CachedConverter{
+		c:          c,
+		maxEntries: max(maxEntries, 1),
+		entries:    make(map[string]*list.Element),
+	}
+	for _, opt := range opts {
+		opt(cc)
+	}
+	return cc
+}
+
+// Convert returns the cached result for input, converting and caching it if
+// there is none. Failed conversions aren't cached.
+func (cc *CachedConverter) Convert(input string) (string, error) {
+	cc.mu.Lock()
+	if e, ok := cc.entries[input]; ok {
+		cc.lru.MoveToFront(e)
+		output := e.Value.(*cacheEntry).output
+		cc.mu.Unlock()
+		return output, nil
+	}
+	cc.mu.Unlock()
+
+	output, err := cc.c.Convert(input)
+	if err != nil {
+		return "", err
+	}
+	if cc.maxInputLen > 0 && len(input) > cc.maxInputLen {
+		return output, nil
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if e, ok := cc.entries[input]; ok {
+		// Converted concurrently
+		cc.lru.MoveToFront(e)
+		return output, nil
+	}
+	cc.entries[input] = cc.lru.PushFront(&cacheEntry{input: input, output: output})
+	for cc.lru.Len() > cc.maxEntries {
+		oldest := cc.lru.Back()
+		cc.lru.Remove(oldest)
+		delete(cc.entries, oldest.Value.(*cacheEntry).input)
+	}
+	return output, nil
+}
+
+// Len returns the number of cached results.
+func (cc *CachedConverter) Len() int {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	return cc.lru.Len()
+}
+
+// Purge drops all cached results.
+func (cc *CachedConverter) Purge() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	clear(cc.entries)
+	cc.lru.Init()
+}
+
+// Close drops all cached results and closes the wrapped Conversion.
+func (cc *CachedConverter) Close() error {
+	cc.Purge()
+	return cc.c.Close()
+}
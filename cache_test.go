@@ -0,0 +1,120 @@
+package opencc
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// countingConverter counts conversions and returns its input upper-cased.
+type countingConverter struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *countingConverter) Convert(input string) (string, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	if input == "fail" {
+		return "", ErrConversionFailed
+	}
+	return strings.ToUpper(input), nil
+}
+
+func (c *countingConverter) Close() error { return nil }
+
+func TestCachedConverter(t *testing.T) {
+	inner := &countingConverter{}
+	cc := NewCachedConverter(inner, 2)
+	defer cc.Close()
+
+	convert := func(input, want string) {
+		t.Helper()
+		got, err := cc.Convert(input)
+		if err != nil || got != want {
+			t.Errorf("Convert(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	convert("a", "A")
+	convert("a", "A")
+	if inner.calls != 1 {
+		t.Errorf("calls = %d, want 1 after a cache hit", inner.calls)
+	}
+
+	convert("b", "B")
+	convert("a", "A") // a is now the most recently used
+	convert("c", "C") // evicts b
+	if cc.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cc.Len())
+	}
+	calls := inner.calls
+	convert("a", "A")
+	if inner.calls != calls {
+		t.Error("most recently used entry was evicted")
+	}
+	convert("b", "B")
+	if inner.calls != calls+1 {
+		t.Error("least recently used entry was not evicted")
+	}
+
+	if _, err := cc.Convert("fail"); !errors.Is(err, ErrConversionFailed) {
+		t.Errorf("Convert() error = %v, want %v", err, ErrConversionFailed)
+	}
+	calls = inner.calls
+	cc.Convert("fail")
+	if inner.calls != calls+1 {
+		t.Error("failed conversion was cached")
+	}
+
+	cc.Purge()
+	if cc.Len() != 0 {
+		t.Errorf("Len() after Purge() = %d, want 0", cc.Len())
+	}
+}
+
+func TestCachedConverterMaxInputLen(t *testing.T) {
+	inner := &countingConverter{}
+	cc := NewCachedConverter(inner, 10, WithMaxInputLen(3))
+
+	cc.Convert("long input")
+	cc.Convert("long input")
+	if inner.calls != 2 || cc.Len() != 0 {
+		t.Errorf("calls = %d, Len() = %d, want long inputs left uncached", inner.calls, cc.Len())
+	}
+}
+
+func TestCachedConverterConcurrent(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	cc := NewCachedConverter(converter, 4)
+	defer cc.Close()
+
+	inputs := []string{"简体", "汉字", "转换", "测试", "中文", "繁体"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				input := inputs[(i+j)%len(inputs)]
+				if _, err := cc.Convert(input); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if cc.Len() > 4 {
+		t.Errorf("Len() = %d, want at most 4", cc.Len())
+	}
+	if got, _ := cc.Convert("简体"); got != "簡體" {
+		t.Errorf("Convert() = %q, want %q", got, "簡體")
+	}
+}
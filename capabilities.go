@@ -0,0 +1,88 @@
+package opencc
+
+import (
+	"context"
+	"slices"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// requiredExports are the functions of the binary every converter calls.
+var requiredExports = []string{
+	"malloc", "free",
+	"opencc_open", "opencc_convert", "opencc_convert_free", "opencc_close",
+}
+
+// Capabilities lists the functions an OpenCC binary exports, so a custom
+// binary given with WithBinary can be checked at startup rather than
+// failing on first use.
+type Capabilities struct {
+	Exports []string // in lexical order
+}
+
+func newCapabilities(exports map[string]api.FunctionDefinition) Capabilities {
+	names := make([]string, 0, len(exports))
+	for name := range exports {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return Capabilities{Exports: names}
+}
+
+// Has reports whether the binary exports the function name, such as
+// "opencc_convert_len", which converters use when it is present.
+func (caps Capabilities) Has(name string) bool {
+	_, found := slices.BinarySearch(caps.Exports, name)
+	return found
+}
+
+// Missing returns the functions every converter needs that the binary
+// lacks. Creating a converter from a binary with missing functions fails
+// with an error wrapping errors.ErrUnsupported.
+func (caps Capabilities) Missing() []string {
+	var missing []string
+	for _, name := range requiredExports {
+		if !caps.Has(name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// BinaryCapabilities reports the exports of the binary converters created
+// with opts use, the embedded one or one given with WithBinary, without
+// instantiating it. See Engine.BinaryCapabilities.
+func BinaryCapabilities(ctx context.Context, opts ...Option) (Capabilities, error) {
+	return defaultEngine.BinaryCapabilities(ctx, opts...)
+}
+
+// BinaryCapabilities reports the exports of the binary converters created
+// in e with opts use. The binary is compiled, and the engine initialized,
+// if it wasn't already.
+func (e *Engine) BinaryCapabilities(ctx context.Context, opts ...Option) (Capabilities, error) {
+	st, err := e.runtime(ctx)
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	o := newOptions(opts)
+	compiled := st.cm
+	if o.binary != nil {
+		if compiled, err = st.compileCustom(ctx, o); err != nil {
+			return Capabilities{}, err
+		}
+	}
+	return newCapabilities(compiled.ExportedFunctions()), nil
+}
+
+// Capabilities reports the exports of the binary c's module was created
+// from.
+func (c *Converter) Capabilities() (Capabilities, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.invalid() {
+		return Capabilities{}, ErrInvalidConverter
+	}
+	return newCapabilities(c.mod.mod.ExportedFunctionDefinitions()), nil
+}
@@ -0,0 +1,61 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestBinaryCapabilities(t *testing.T) {
+	caps, err := BinaryCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("BinaryCapabilities() error = %v", err)
+	}
+	if missing := caps.Missing(); len(missing) > 0 {
+		t.Errorf("Missing() = %v, want none", missing)
+	}
+	if !caps.Has("opencc_convert") || caps.Has("opencc_nonexistent") {
+		t.Errorf("Has() doesn't match Exports %v", caps.Exports)
+	}
+
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	got, err := converter.Capabilities()
+	if err != nil {
+		t.Fatalf("Converter.Capabilities() error = %v", err)
+	}
+	if !slices.Equal(got.Exports, caps.Exports) {
+		t.Errorf("Converter.Capabilities() = %v, want %v", got.Exports, caps.Exports)
+	}
+
+	converter.Close()
+	if _, err := converter.Capabilities(); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("Capabilities() after Close error = %v, want %v", err, ErrInvalidConverter)
+	}
+}
+
+func TestBinaryCapabilitiesMissing(t *testing.T) {
+	// The smallest valid module, which exports nothing
+	empty := []byte("\x00asm\x01\x00\x00\x00")
+
+	caps, err := BinaryCapabilities(context.Background(), WithBinary(empty))
+	if err != nil {
+		t.Fatalf("BinaryCapabilities() error = %v", err)
+	}
+	if len(caps.Exports) != 0 {
+		t.Errorf("Exports = %v, want none", caps.Exports)
+	}
+	if missing := caps.Missing(); !slices.Equal(missing, requiredExports) {
+		t.Errorf("Missing() = %v, want %v", missing, requiredExports)
+	}
+
+	if converter, err := NewConverter("s2t.json", WithBinary(empty)); !errors.Is(err, errors.ErrUnsupported) {
+		if err == nil {
+			converter.Close()
+		}
+		t.Errorf("NewConverter() error = %v, want %v", err, errors.ErrUnsupported)
+	}
+}
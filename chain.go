@@ -0,0 +1,56 @@
+package opencc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ConverterChain runs text through several converters in sequence, such as
+// s2t.json followed by t2jp.json to convert Simplified Chinese to Japanese
+// Shinjitai. Like a Converter, it is safe for concurrent use.
+type ConverterChain struct {
+	stages []*Converter
+}
+
+var _ Conversion = (*ConverterChain)(nil)
+
+// NewConverterChain opens a converter for each of configs. Every
+// configuration is checked before any converter is opened, and if any of
+// them can't be opened the whole chain fails.
+func NewConverterChain(configs ...string) (*ConverterChain, error) {
+	stages, err := openConverters("converter chain", configs)
+	if err != nil {
+		return nil, err
+	}
+	return &ConverterChain{stages: stages}, nil
+}
+
+// Convert converts input with each converter of the chain in turn.
+func (ch *ConverterChain) Convert(input string) (string, error) {
+	result, err := ch.ConvertBytes([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// ConvertBytes is like Convert for UTF-8 encoded input.
+func (ch *ConverterChain) ConvertBytes(input []byte) ([]byte, error) {
+	result := input
+	for _, c := range ch.stages {
+		var err error
+		if result, err = c.ConvertBytes(result); err != nil {
+			return nil, fmt.Errorf("%s: %w", c.config, err)
+		}
+	}
+	return result, nil
+}
+
+// Close closes every converter of the chain.
+func (ch *ConverterChain) Close() error {
+	var errs []error
+	for _, c := range ch.stages {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,61 @@
+package opencc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConverterChain(t *testing.T) {
+	requireConfigs(t, "t2jp.json")
+	chain, err := NewConverterChain("s2t.json", "t2jp.json")
+	if err != nil {
+		t.Fatalf("NewConverterChain() error = %v", err)
+	}
+
+	got, err := chain.Convert("学国")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if got != "学国" {
+		t.Errorf("Convert() = %q, want %q", got, "学国")
+	}
+
+	got, err = chain.Convert("简体")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if want := "簡体"; got != want {
+		t.Errorf("Convert() = %q, want %q", got, want)
+	}
+
+	if err := chain.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := chain.Convert("简体"); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("Convert() after Close() error = %v, want %v", err, ErrInvalidConverter)
+	}
+}
+
+func TestConverterChainInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs []string
+		wantErr error
+	}{
+		{"MissingConfig", []string{"s2t.json", "does-not-exist.json"}, ErrConfigNotFound},
+		{"Empty", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := NewConverterChain(tt.configs...)
+			if err == nil {
+				chain.Close()
+				t.Fatal("NewConverterChain() error = nil, want non-nil")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewConverterChain() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
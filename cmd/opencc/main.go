@@ -0,0 +1,196 @@
+// Command opencc converts text between Chinese variants with OpenCC.
+//
+// It reads standard input, or the file named by -in, and writes the
+// conversion to standard output, or the file named by -out:
+//
+//	opencc -config s2twp < simplified.txt > traditional.txt
+//
+// -list prints the bundled configurations. With -workers greater than 1,
+// lines are converted in parallel.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bestnite/go-opencc"
+)
+
+// linesPerRound is how many lines are converted in parallel at a time with
+// -workers.
+const linesPerRound = 4096
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("opencc", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	config := flags.String("config", "s2t", "conversion `config`, such as s2t, t2s or s2twp")
+	in := flags.String("in", "", "input `file` (default standard input)")
+	out := flags.String("out", "", "output `file` (default standard output)")
+	workers := flags.Int("workers", 1, "number of lines converted in parallel")
+	list := flags.Bool("list", false, "list the bundled configurations and exit")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(stderr, "opencc: unexpected arguments: %s\n", strings.Join(flags.Args(), " "))
+		flags.Usage()
+		return 2
+	}
+
+	if *list {
+		configs, err := opencc.ListConfigs()
+		if err != nil {
+			fmt.Fprintf(stderr, "opencc: %v\n", err)
+			return 1
+		}
+		for _, name := range configs {
+			fmt.Fprintln(stdout, strings.TrimSuffix(name, ".json"))
+		}
+		return 0
+	}
+
+	if err := convert(*config, *in, *out, *workers, stdin, stdout); err != nil {
+		fmt.Fprintf(stderr, "opencc: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// convert converts in to out with config, using standard input and output
+// for empty names.
+func convert(config, in, out string, workers int, stdin io.Reader, stdout io.Writer) (err error) {
+	if !strings.HasSuffix(config, ".json") {
+		config += ".json"
+	}
+	if out == "" {
+		return convertTo(config, in, workers, stdin, stdout)
+	}
+
+	// Write to a temporary file that replaces out once the conversion has
+	// succeeded, so out can be the input file and isn't left half written
+	// on failure
+	f, err := os.CreateTemp(filepath.Dir(out), "."+filepath.Base(out)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	// Keep the mode of a file being replaced, and otherwise use the one
+	// os.Create gives with the usual umask
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(out); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := f.Chmod(mode); err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		return err
+	}
+
+	if err := convertTo(config, in, workers, stdin, f); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), out)
+}
+
+// convertTo converts in, or stdin if it is empty, to w with config.
+func convertTo(config, in string, workers int, stdin io.Reader, w io.Writer) error {
+	r := stdin
+	if in != "" {
+		f, err := os.Open(in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if workers > 1 {
+		pool := opencc.NewConverterPool(config)
+		defer pool.Close()
+		return convertLines(pool, r, w, workers)
+	}
+
+	c, err := opencc.NewConverter(config)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	return c.ConvertStream(r, w)
+}
+
+// convertLines converts r to w line by line on up to workers converters,
+// keeping line endings, including a missing final newline, as they were.
+func convertLines(pool *opencc.ConverterPool, r io.Reader, w io.Writer, workers int) error {
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+
+	lines := make([]string, 0, linesPerRound)
+	for first := true; ; first = false {
+		line, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		eof := err != nil
+		if first {
+			// Strip a byte order mark, as ConvertStream does; lines are
+			// converted as pieces of text, which keep U+FEFF
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+
+		if len(lines) == linesPerRound || (eof && len(lines) > 0) {
+			if err := writeLines(pool, bw, lines, workers); err != nil {
+				return err
+			}
+			lines = lines[:0]
+		}
+		if eof {
+			return bw.Flush()
+		}
+	}
+}
+
+// writeLines converts lines, each ending in a newline unless it is the last
+// line of the input, and writes them to w.
+func writeLines(pool *opencc.ConverterPool, w io.Writer, lines []string, workers int) error {
+	trimmed := make([]string, len(lines))
+	for i, line := range lines {
+		trimmed[i] = strings.TrimSuffix(line, "\n")
+	}
+
+	converted, err := pool.ConvertLines(trimmed, workers)
+	if err != nil {
+		return err
+	}
+	for i, line := range converted {
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+		if strings.HasSuffix(lines[i], "\n") {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	// A byte order mark is stripped, but not U+FEFF elsewhere
+	input := "\ufeff简体字\r\n汉字\n\n\ufeff这是一个测试"
+	want := "簡體字\r\n漢字\n\n\ufeff這是一個測試"
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"stream", nil},
+		{"config with extension", []string{"-config", "s2t.json"}},
+		{"workers", []string{"-workers", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, strings.NewReader(input), &stdout, &stderr); code != 0 {
+				t.Fatalf("run() = %d, stderr %q", code, stderr.String())
+			}
+			if stdout.String() != want {
+				t.Errorf("output = %q, want %q", stdout.String(), want)
+			}
+		})
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.txt")
+	out := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(in, []byte("繁體字\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-config", "t2s", "-in", in, "-out", out}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, stderr.String())
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "繁体字\n" {
+		t.Errorf("output file = %q, want %q", got, "繁体字\n")
+	}
+}
+
+func TestRunInPlace(t *testing.T) {
+	for _, workers := range []string{"1", "2"} {
+		t.Run("workers="+workers, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "text.txt")
+			if err := os.WriteFile(path, []byte("繁體字\n漢字\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			var stdout, stderr bytes.Buffer
+			args := []string{"-config", "t2s", "-workers", workers, "-in", path, "-out", path}
+			if code := run(args, nil, &stdout, &stderr); code != 0 {
+				t.Fatalf("run() = %d, stderr %q", code, stderr.String())
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "繁体字\n汉字\n"; string(got) != want {
+				t.Errorf("output file = %q, want %q", got, want)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0o600 {
+				t.Errorf("output file mode = %v, want %v", info.Mode().Perm(), fs.FileMode(0o600))
+			}
+
+			// A failed conversion leaves the file as it was
+			args[1] = "missing"
+			if code := run(args, nil, &stdout, &stderr); code != 1 {
+				t.Errorf("run() with a missing config = %d, want 1", code)
+			}
+			if got, err := os.ReadFile(path); err != nil || string(got) != "繁体字\n汉字\n" {
+				t.Errorf("output file after a failure = %q, %v, want it unchanged", got, err)
+			}
+
+			// Only the output file is left behind
+			if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+				t.Errorf("ReadDir() = %v, %v, want only %s", entries, err, filepath.Base(path))
+			}
+		})
+	}
+}
+
+func TestRunList(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-list"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, stderr %q", code, stderr.String())
+	}
+	if configs := strings.Fields(stdout.String()); !strings.Contains(" "+strings.Join(configs, " ")+" ", " s2t ") {
+		t.Errorf("-list output = %q, want it to list s2t", stdout.String())
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"unknown config", []string{"-config", "missing"}, 1},
+		{"missing input", []string{"-in", filepath.Join(t.TempDir(), "missing.txt")}, 1},
+		{"unknown flag", []string{"-bogus"}, 2},
+		{"extra arguments", []string{"file.txt"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, strings.NewReader("简体"), &stdout, &stderr); code != tt.code {
+				t.Errorf("run() = %d, want %d", code, tt.code)
+			}
+			if stderr.Len() == 0 {
+				t.Error("stderr is empty, want an error message")
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package opencc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ConfigInfo describes what a configuration file does: how OpenCC segments
+// the input and the conversion steps it then applies in order.
+type ConfigInfo struct {
+	Name         string           `json:"name"`
+	Segmentation SegmentationInfo `json:"segmentation"`
+	Steps        []ConversionStep `json:"conversion_chain"`
+
+	fsys fs.FS  // the configuration was read from
+	dir  string // of the configuration in fsys
+}
+
+// SegmentationInfo describes how a configuration segments its input.
+type SegmentationInfo struct {
+	Type string   `json:"type"` // such as "mmseg"
+	Dict DictInfo `json:"dict"`
+}
+
+// ConversionStep is one step of a configuration's conversion chain.
+type ConversionStep struct {
+	Dict DictInfo `json:"dict"`
+}
+
+// DictInfo describes a dictionary. A dictionary of type "group" combines
+// Dicts, the first match winning; other types are read from File, which is
+// relative to the configuration file.
+type DictInfo struct {
+	Type  string     `json:"type"` // such as "ocd2", "text" or "group"
+	File  string     `json:"file,omitempty"`
+	Dicts []DictInfo `json:"dicts,omitempty"`
+}
+
+// Files returns the dictionary files d reads, in order.
+func (d DictInfo) Files() []string {
+	if d.File != "" {
+		return []string{d.File}
+	}
+	var files []string
+	for _, dict := range d.Dicts {
+		files = append(files, dict.Files()...)
+	}
+	return files
+}
+
+// ParseConfig reads and parses configFile without opening a converter. It
+// looks configFile up where NewConverter would with the same options, so
+// WithDataFS is honored. A missing configuration returns ErrConfigNotFound.
+func ParseConfig(configFile string, opts ...Option) (*ConfigInfo, error) {
+	root, err := newOptions(opts).root()
+	if err != nil {
+		return nil, fmt.Errorf("create data sub-filesystem: %w", err)
+	}
+
+	name := path.Clean(strings.TrimPrefix(configFile, "/"))
+	data, err := fs.ReadFile(root, name)
+	if err != nil {
+		return nil, &ConversionError{Op: "open", Config: configFile, Err: ErrConfigNotFound}
+	}
+
+	info := &ConfigInfo{fsys: root, dir: path.Dir(name)}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", configFile, err)
+	}
+	if len(info.Steps) == 0 {
+		return nil, fmt.Errorf("%s: no conversion chain", configFile)
+	}
+	return info, nil
+}
+
+// Files returns the dictionary files the configuration reads, each once,
+// in the order OpenCC loads them.
+func (info *ConfigInfo) Files() []string {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(d DictInfo) {
+		for _, file := range d.Files() {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+
+	add(info.Segmentation.Dict)
+	for _, step := range info.Steps {
+		add(step.Dict)
+	}
+	return files
+}
+
+// MissingFiles returns the dictionary files of the configuration that are
+// missing from the filesystem it was read from. A configuration with
+// missing files can't be opened.
+func (info *ConfigInfo) MissingFiles() []string {
+	var missing []string
+	for _, file := range info.Files() {
+		if _, err := fs.Stat(info.fsys, path.Join(info.dir, file)); err != nil {
+			missing = append(missing, file)
+		}
+	}
+	return missing
+}
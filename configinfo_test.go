@@ -0,0 +1,81 @@
+package opencc
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseConfig(t *testing.T) {
+	requireConfigs(t, "s2twp.json")
+	info, err := ParseConfig("s2twp.json")
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+
+	if info.Name == "" {
+		t.Error("Name is empty")
+	}
+	if info.Segmentation.Type != "mmseg" || info.Segmentation.Dict.File != "STPhrases.ocd2" {
+		t.Errorf("Segmentation = %+v, want mmseg with STPhrases.ocd2", info.Segmentation)
+	}
+	if len(info.Steps) != 3 {
+		t.Fatalf("len(Steps) = %d, want 3", len(info.Steps))
+	}
+	if group := info.Steps[0].Dict; group.Type != "group" || len(group.Dicts) != 2 {
+		t.Errorf("Steps[0].Dict = %+v, want a group of 2 dictionaries", group)
+	}
+
+	want := []string{"STPhrases.ocd2", "STCharacters.ocd2", "TWPhrases.ocd2", "TWVariants.ocd2"}
+	if got := info.Files(); !slices.Equal(got, want) {
+		t.Errorf("Files() = %v, want %v", got, want)
+	}
+}
+
+func TestParseConfigBundled(t *testing.T) {
+	configs, err := ListConfigs()
+	if err != nil {
+		t.Fatalf("ListConfigs() error = %v", err)
+	}
+
+	for _, config := range configs {
+		info, err := ParseConfig(config)
+		if err != nil {
+			t.Errorf("ParseConfig(%q) error = %v", config, err)
+			continue
+		}
+		if missing := info.MissingFiles(); len(missing) > 0 {
+			t.Errorf("ParseConfig(%q).MissingFiles() = %v, want none", config, missing)
+		}
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	config, err := readEmbedded("s2t.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"s2t.json":     &fstest.MapFile{Data: config},
+		"invalid.json": &fstest.MapFile{Data: []byte("{")},
+		"empty.json":   &fstest.MapFile{Data: []byte(`{"name": "empty"}`)},
+	}
+
+	info, err := ParseConfig("s2t.json", WithDataFS(fsys))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	if got, want := info.MissingFiles(), []string{"STPhrases.ocd2", "STCharacters.ocd2"}; !slices.Equal(got, want) {
+		t.Errorf("MissingFiles() = %v, want %v", got, want)
+	}
+
+	if _, err := ParseConfig("missing.json", WithDataFS(fsys)); !errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("ParseConfig(missing) error = %v, want %v", err, ErrConfigNotFound)
+	}
+	for _, name := range []string{"invalid.json", "empty.json"} {
+		if _, err := ParseConfig(name, WithDataFS(fsys)); err == nil {
+			t.Errorf("ParseConfig(%q) error = nil, want non-nil", name)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package opencc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sync"
+)
+
+// Config names one of the bundled conversion configurations.
+type Config string
+
+// Bundled configurations.
+const (
+	ConfigS2T   Config = "s2t.json"   // Simplified to Traditional Chinese
+	ConfigT2S   Config = "t2s.json"   // Traditional to Simplified Chinese
+	ConfigS2TW  Config = "s2tw.json"  // Simplified to Traditional Chinese (Taiwan)
+	ConfigTW2S  Config = "tw2s.json"  // Traditional Chinese (Taiwan) to Simplified
+	ConfigS2TWP Config = "s2twp.json" // Simplified to Traditional Chinese (Taiwan) with Taiwan vocabulary
+	ConfigTW2SP Config = "tw2sp.json" // Traditional Chinese (Taiwan) to Simplified with mainland vocabulary
+	ConfigS2HK  Config = "s2hk.json"  // Simplified to Traditional Chinese (Hong Kong)
+	ConfigHK2S  Config = "hk2s.json"  // Traditional Chinese (Hong Kong) to Simplified
+	ConfigT2TW  Config = "t2tw.json"  // Traditional to Traditional Chinese (Taiwan)
+	ConfigTW2T  Config = "tw2t.json"  // Traditional Chinese (Taiwan) to Traditional
+	ConfigT2HK  Config = "t2hk.json"  // Traditional to Traditional Chinese (Hong Kong)
+	ConfigHK2T  Config = "hk2t.json"  // Traditional Chinese (Hong Kong) to Traditional
+	ConfigT2JP  Config = "t2jp.json"  // Traditional Chinese to Japanese Shinjitai
+	ConfigJP2T  Config = "jp2t.json"  // Japanese Shinjitai to Traditional Chinese
+)
+
+// NewConverterConfig creates a new OpenCC converter for one of the bundled
+// configurations.
+func NewConverterConfig(config Config, opts ...Option) (*Converter, error) {
+	return NewConverter(string(config), opts...)
+}
+
+// ListConfigs returns the names of the bundled conversion configurations,
+// such as "s2t.json", in lexical order. Any of them can be passed to
+// NewConverter.
+func ListConfigs() ([]string, error) {
+	root, err := dataSubFS()
+	if err != nil {
+		return nil, fmt.Errorf("create data sub-filesystem: %w", err)
+	}
+	return listConfigs(root)
+}
+
+// bundledConfigs caches ListConfigs, as the embedded data never changes.
+var bundledConfigs = sync.OnceValues(ListConfigs)
+
+func listConfigs(fsys fs.FS) ([]string, error) {
+	var configs []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(name) != ".json" {
+			return nil
+		}
+
+		// Build artifacts such as InstallScripts.json end up next to the
+		// configs, so only keep files that describe a conversion
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		var config struct {
+			ConversionChain json.RawMessage `json:"conversion_chain"`
+		}
+		if json.Unmarshal(data, &config) == nil && config.ConversionChain != nil {
+			configs = append(configs, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list configs: %w", err)
+	}
+
+	slices.Sort(configs)
+	return configs, nil
+}
@@ -0,0 +1,59 @@
+package opencc
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestListConfigs(t *testing.T) {
+	configs, err := ListConfigs()
+	if err != nil {
+		t.Fatalf("ListConfigs() error = %v", err)
+	}
+
+	// Build tags only leave out the Taiwan, Hong Kong and Japanese data
+	for _, want := range []string{"s2t.json", "t2s.json"} {
+		if !slices.Contains(configs, want) {
+			t.Errorf("ListConfigs() = %v, missing %v", configs, want)
+		}
+	}
+	if slices.Contains(configs, "InstallScripts.json") {
+		t.Errorf("ListConfigs() = %v, want no build artifacts", configs)
+	}
+	if !slices.IsSorted(configs) {
+		t.Errorf("ListConfigs() = %v, want sorted", configs)
+	}
+}
+
+func TestConfigConstants(t *testing.T) {
+	all := []Config{
+		ConfigS2T, ConfigT2S, ConfigS2TW, ConfigTW2S, ConfigS2TWP, ConfigTW2SP,
+		ConfigS2HK, ConfigHK2S, ConfigT2TW, ConfigTW2T, ConfigT2HK, ConfigHK2T,
+		ConfigT2JP, ConfigJP2T,
+	}
+	// Check against the data directory, as build tags may leave a config
+	// out of the embedded data
+	for _, config := range all {
+		if _, err := os.Stat(filepath.Join("data", string(config))); err != nil {
+			t.Errorf("Config %q is not bundled: %v", config, err)
+		}
+	}
+}
+
+func TestNewConverterConfig(t *testing.T) {
+	converter, err := NewConverterConfig(ConfigS2T)
+	if err != nil {
+		t.Fatalf("NewConverterConfig() error = %v", err)
+	}
+	defer converter.Close()
+
+	result, err := converter.Convert("简体字")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result != "簡體字" {
+		t.Errorf("Convert() = %v, want %v", result, "簡體字")
+	}
+}
@@ -0,0 +1,37 @@
+package opencc
+
+// Conversion is the interface implemented by Converter, ConverterPool,
+// ConverterChain and CachedConverter. Code that only needs to convert text
+// can accept a Conversion, so tests can substitute a NopConverter or a fake
+// without loading the WASM runtime.
+type Conversion interface {
+	Convert(input string) (string, error)
+	Close() error
+}
+
+var (
+	_ Conversion = (*Converter)(nil)
+	_ Conversion = (*ConverterPool)(nil)
+	_ Conversion = NopConverter{}
+)
+
+// NopConverter is a Conversion that returns its input unchanged. Use it
+// where conversion is turned off, for example by a feature flag, so the
+// rest of the code can convert unconditionally:
+//
+//	var conv opencc.Conversion = opencc.NopConverter{}
+//	if cfg.ConvertToTraditional {
+//		conv = opencc.NewConverterPool("s2t.json")
+//	}
+//	defer conv.Close()
+type NopConverter struct{}
+
+// Convert returns input unchanged.
+func (NopConverter) Convert(input string) (string, error) {
+	return input, nil
+}
+
+// Close does nothing.
+func (NopConverter) Close() error {
+	return nil
+}
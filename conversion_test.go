@@ -0,0 +1,18 @@
+package opencc
+
+import "testing"
+
+func TestNopConverter(t *testing.T) {
+	var c Conversion = NopConverter{}
+	defer c.Close()
+
+	for _, input := range []string{"", "简体字", "繁體字", "Hello"} {
+		got, err := c.Convert(input)
+		if err != nil {
+			t.Errorf("Convert(%q) error = %v", input, err)
+		}
+		if got != input {
+			t.Errorf("Convert(%q) = %q, want the input unchanged", input, got)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package opencc
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSVOption configures ConvertCSV.
+type CSVOption func(*csvOptions)
+
+type csvOptions struct {
+	header bool
+	comma  rune
+}
+
+// WithCSVHeader makes ConvertCSV copy the first record unconverted, for
+// files whose header row names the columns.
+func WithCSVHeader() CSVOption {
+	return func(o *csvOptions) {
+		o.header = true
+	}
+}
+
+// WithCSVComma sets the field delimiter, such as '\t' for TSV. It defaults
+// to ','.
+func WithCSVComma(comma rune) CSVOption {
+	return func(o *csvOptions) {
+		o.comma = comma
+	}
+}
+
+// ConvertCSV reads CSV records from r, converts the fields at the given
+// zero-based column indices with c, and writes the records to w. Everything
+// else is copied byte for byte: the other columns, such as IDs and numbers,
+// the quoting of each field, blank lines and line endings. A converted field
+// that was quoted stays quoted. Rows may have different numbers of fields;
+// columns a row doesn't have are skipped. Quoted fields may contain
+// delimiters and newlines.
+//
+// encoding/csv drops the quoting and line endings it read, so records are
+// split here instead, following its rules and reporting its errors; a
+// stray quote inside an unquoted field is an error, as with LazyQuotes
+// unset.
+func ConvertCSV(c *Converter, r io.Reader, w io.Writer, columns []int, opts ...CSVOption) error {
+	o := csvOptions{comma: ','}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for _, col := range columns {
+		if col < 0 {
+			return fmt.Errorf("convert CSV: invalid column %d", col)
+		}
+	}
+	if o.comma == '"' || o.comma == '\r' || o.comma == '\n' || o.comma == utf8.RuneError || !utf8.ValidRune(o.comma) {
+		return fmt.Errorf("convert CSV: invalid delimiter %q", o.comma)
+	}
+	comma := string(o.comma)
+
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	skip := o.header
+	for line := 1; ; {
+		record, err := readCSVRecord(br, comma)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("convert CSV: %w", err)
+		}
+		if record == "" {
+			break
+		}
+
+		start := line
+		line += strings.Count(record, "\n")
+		body := strings.TrimSuffix(strings.TrimSuffix(record, "\n"), "\r")
+		if body == "" {
+			// Blank lines aren't records, as in encoding/csv
+			bw.WriteString(record)
+			continue
+		}
+		if skip {
+			skip = false
+			bw.WriteString(record)
+			continue
+		}
+
+		fields, err := splitCSVFields(body, comma, start)
+		if err != nil {
+			return fmt.Errorf("convert CSV: %w", err)
+		}
+		for _, col := range columns {
+			if col >= len(fields) {
+				continue
+			}
+			if fields[col], err = convertCSVField(c, fields[col], comma); err != nil {
+				row := start + strings.Count(strings.Join(fields[:col], comma), "\n")
+				return fmt.Errorf("convert CSV: line %d, column %d: %w", row, col, err)
+			}
+		}
+		bw.WriteString(strings.Join(fields, comma))
+		bw.WriteString(record[len(body):])
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("convert CSV: %w", err)
+	}
+	return nil
+}
+
+// readCSVRecord reads a record, with its line ending, from r. A record
+// continues onto the next line only while a quoted field is open; a quote
+// that doesn't start a field opens none, so it can't swallow the lines
+// after it. splitCSVFields reports such quotes.
+func readCSVRecord(r *bufio.Reader, comma string) (string, error) {
+	var record strings.Builder
+	quoted, fieldStart := false, true
+	for {
+		line, err := r.ReadString('\n')
+		record.WriteString(line)
+		for i := 0; i < len(line); {
+			switch {
+			case quoted:
+				// Find the closing quote, skipping escaped ones
+				j := strings.IndexByte(line[i:], '"')
+				if j < 0 {
+					i = len(line)
+					continue
+				}
+				i += j + 1
+				if strings.HasPrefix(line[i:], `"`) {
+					i++
+					continue
+				}
+				quoted, fieldStart = false, false
+			case fieldStart && line[i] == '"':
+				quoted = true
+				i++
+			default:
+				j := strings.Index(line[i:], comma)
+				if j < 0 {
+					i = len(line)
+					continue
+				}
+				i += j + len(comma)
+				fieldStart = true
+			}
+		}
+		if err != nil || !quoted {
+			return record.String(), err
+		}
+	}
+}
+
+// splitCSVFields splits a record without its line ending into its fields,
+// each with its quotes as written. start is the line the record starts on,
+// for errors.
+func splitCSVFields(record, comma string, start int) ([]string, error) {
+	var fields []string
+	for pos := 0; ; {
+		end := pos
+		if strings.HasPrefix(record[pos:], `"`) {
+			// Find the closing quote, skipping escaped ones
+			end++
+			for {
+				i := strings.IndexByte(record[end:], '"')
+				if i < 0 {
+					return nil, csvError(record, start, len(record), csv.ErrQuote)
+				}
+				end += i + 1
+				if !strings.HasPrefix(record[end:], `"`) {
+					break
+				}
+				end++
+			}
+			if end < len(record) && !strings.HasPrefix(record[end:], comma) {
+				return nil, csvError(record, start, end, csv.ErrQuote)
+			}
+		} else {
+			end = len(record)
+			if i := strings.Index(record[pos:], comma); i >= 0 {
+				end = pos + i
+			}
+			if i := strings.IndexByte(record[pos:end], '"'); i >= 0 {
+				return nil, csvError(record, start, pos+i, csv.ErrBareQuote)
+			}
+		}
+
+		fields = append(fields, record[pos:end])
+		if end == len(record) {
+			return fields, nil
+		}
+		pos = end + len(comma)
+	}
+}
+
+// csvError reports err at byte offset pos of a record starting on line
+// start, the way encoding/csv does.
+func csvError(record string, start, pos int, err error) error {
+	line := start + strings.Count(record[:pos], "\n")
+	col := pos - strings.LastIndexByte(record[:pos], '\n')
+	return &csv.ParseError{StartLine: start, Line: line, Column: col, Err: err}
+}
+
+// convertCSVField converts a field as written in the record, keeping its
+// quotes, and adding them if the conversion needs them.
+func convertCSVField(c *Converter, field, comma string) (string, error) {
+	quoted := strings.HasPrefix(field, `"`)
+	text := field
+	if quoted {
+		text = strings.ReplaceAll(field[1:len(field)-1], `""`, `"`)
+	}
+	if text == "" {
+		return field, nil
+	}
+
+	converted, err := c.convertPart(text)
+	if err != nil {
+		return "", err
+	}
+	if quoted || strings.Contains(converted, comma) || strings.ContainsAny(converted, "\"\r\n") {
+		return `"` + strings.ReplaceAll(converted, `"`, `""`) + `"`, nil
+	}
+	return converted, nil
+}
@@ -0,0 +1,154 @@
+package opencc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestConvertCSV(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		name    string
+		input   string
+		columns []int
+		opts    []CSVOption
+		want    string
+	}{
+		{
+			name:    "selected columns",
+			input:   "1,简体,汉字\n2,汉字,简体\n",
+			columns: []int{1},
+			want:    "1,簡體,汉字\n2,漢字,简体\n",
+		},
+		{
+			name:    "header",
+			input:   "id,名称\n1,简体\n",
+			columns: []int{1},
+			opts:    []CSVOption{WithCSVHeader()},
+			want:    "id,名称\n1,簡體\n",
+		},
+		{
+			name:    "ragged rows",
+			input:   "1\n2,汉字,x\n3,简体\n",
+			columns: []int{1, 5},
+			want:    "1\n2,漢字,x\n3,簡體\n",
+		},
+		{
+			name:    "quoted fields",
+			input:   "1,\"简体,汉字\"\n2,\"简\n体\"\n3,\"说\"\"汉\"\"字\"\n",
+			columns: []int{1},
+			want:    "1,\"簡體,漢字\"\n2,\"簡\n體\"\n3,\"說\"\"漢\"\"字\"\n",
+		},
+		{
+			name:    "quoting and CRLF kept",
+			input:   "\"1\",\"简体\",\"汉字\"\r\n2,汉字,\"a,b\"\r\n",
+			columns: []int{1},
+			want:    "\"1\",\"簡體\",\"汉字\"\r\n2,漢字,\"a,b\"\r\n",
+		},
+		{
+			name:    "quoted newline with CRLF",
+			input:   "1,\"简\r\n体\",\"x\"\r\n",
+			columns: []int{1},
+			want:    "1,\"簡\r\n體\",\"x\"\r\n",
+		},
+		{
+			name:    "blank lines before header",
+			input:   "\nid,名称\n\n1,简体",
+			columns: []int{1},
+			opts:    []CSVOption{WithCSVHeader()},
+			want:    "\nid,名称\n\n1,簡體",
+		},
+		{
+			name:    "empty fields",
+			input:   "1,,\"\",简体,\n",
+			columns: []int{1, 2, 3, 4},
+			want:    "1,,\"\",簡體,\n",
+		},
+		{
+			name:    "tab separated",
+			input:   "1\t简体\n",
+			columns: []int{1},
+			opts:    []CSVOption{WithCSVComma('\t')},
+			want:    "1\t簡體\n",
+		},
+		{
+			name:    "empty",
+			input:   "",
+			columns: []int{0},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := ConvertCSV(converter, strings.NewReader(tt.input), &out, tt.columns, tt.opts...); err != nil {
+				t.Fatalf("ConvertCSV() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("ConvertCSV() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertCSVErrors(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		name    string
+		input   string
+		columns []int
+		wantErr error
+		wantMsg string
+	}{
+		{"negative column", "a\n", []int{-1}, nil, "invalid column -1"},
+		{"bad quoting", "1,\"简体\n", []int{1}, nil, "convert CSV"},
+		{"unterminated quote", "1,\"简体\n2,x\n", []int{1}, csv.ErrQuote, "convert CSV"},
+		{"text after quote", "1,\"简\"体\n", []int{1}, csv.ErrQuote, "convert CSV"},
+		{"bare quote", "1,简\"体\n", []int{1}, csv.ErrBareQuote, "convert CSV"},
+		{"conversion failure", "1,ok\n2,a\x00b\n", []int{1}, ErrInvalidInput, "line 2, column 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ConvertCSV(converter, strings.NewReader(tt.input), new(bytes.Buffer), tt.columns)
+			if err == nil {
+				t.Fatal("ConvertCSV() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ConvertCSV() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("ConvertCSV() error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+
+	// A bare quote opens no quoted field, so the record ends with its line
+	// and the rest of the input isn't read
+	r := io.MultiReader(strings.NewReader("1,简\"体\n2,x\n"), iotest.ErrReader(errors.New("read past the record")))
+	err = ConvertCSV(converter, r, new(bytes.Buffer), []int{1})
+	var parseErr *csv.ParseError
+	if !errors.As(err, &parseErr) || !errors.Is(err, csv.ErrBareQuote) || parseErr.StartLine != 1 || parseErr.Line != 1 {
+		t.Errorf("ConvertCSV() with a bare quote error = %v, want %v on line 1", err, csv.ErrBareQuote)
+	}
+
+	if err := ConvertCSV(converter, strings.NewReader("1\n"), new(bytes.Buffer), []int{0}, WithCSVComma('"')); err == nil {
+		t.Error("ConvertCSV() with a quote delimiter error = nil, want error")
+	}
+}
@@ -0,0 +1,144 @@
+package opencc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"testing/fstest"
+	"unicode/utf8"
+)
+
+// customDictFile is the name of the i-th custom dictionary, next to the
+// configuration that uses it.
+func customDictFile(i int) string {
+	return fmt.Sprintf("opencc-custom-%d.txt", i)
+}
+
+// applyCustomDicts mounts a copy of configFile that uses the custom
+// dictionaries over the data filesystem.
+func (o *options) applyCustomDicts(configFile string) error {
+	if len(o.customDicts) == 0 {
+		return nil
+	}
+
+	root, err := o.root()
+	if err != nil {
+		return fmt.Errorf("create data sub-filesystem: %w", err)
+	}
+	name := path.Clean(strings.TrimPrefix(configFile, "/"))
+	data, err := fs.ReadFile(root, name)
+	if err != nil {
+		return &ConversionError{Op: "open", Config: configFile, Err: ErrConfigNotFound}
+	}
+
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("parse %s: %w", configFile, err)
+	}
+
+	files := make(fstest.MapFS)
+	var dicts []any
+	for i, read := range o.customDicts {
+		dict, err := read()
+		if err != nil {
+			return fmt.Errorf("read custom dictionary: %w", err)
+		}
+		dict, err = parseCustomDict(dict)
+		if err != nil {
+			return fmt.Errorf("custom dictionary %d: %w", i, err)
+		}
+
+		file := customDictFile(i)
+		files[path.Join(path.Dir(name), file)] = &fstest.MapFile{Data: dict}
+		dicts = append(dicts, map[string]any{"type": "text", "file": file})
+	}
+
+	if seg, ok := config["segmentation"].(map[string]any); ok {
+		prependDicts(seg, dicts)
+	}
+	chain, ok := config["conversion_chain"].([]any)
+	if !ok || len(chain) == 0 {
+		return fmt.Errorf("%s: no conversion chain", configFile)
+	}
+	step, ok := chain[0].(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: invalid conversion chain", configFile)
+	}
+	prependDicts(step, dicts)
+
+	data, err = json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	files[name] = &fstest.MapFile{Data: data}
+	o.dataFS = overlayFS{files: files, base: root}
+	return nil
+}
+
+// prependDicts replaces the "dict" member of obj with a group of dicts
+// followed by the original dictionary.
+func prependDicts(obj map[string]any, dicts []any) {
+	group := slices.Clone(dicts)
+	if dict, ok := obj["dict"]; ok {
+		group = append(group, dict)
+	}
+	obj["dict"] = map[string]any{"type": "group", "dicts": group}
+}
+
+// parseCustomDict checks a tab-separated dictionary and returns it in the
+// format of OpenCC text dictionaries, sorted by key. Where a key appears
+// more than once, the first entry is kept.
+func parseCustomDict(data []byte) ([]byte, error) {
+	type entry struct{ key, value string }
+	var entries []entry
+	seen := make(map[string]bool)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "\t")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("line %d: want a term and its conversion separated by a tab", i+1)
+		}
+		if !utf8.ValidString(line) {
+			return nil, fmt.Errorf("line %d: invalid UTF-8", i+1)
+		}
+		if !seen[key] {
+			seen[key] = true
+			entries = append(entries, entry{key, value})
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b entry) int {
+		return strings.Compare(a.key, b.key)
+	})
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		buf.WriteString(e.key)
+		buf.WriteByte('\t')
+		buf.WriteString(e.value)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// overlayFS serves files from files in preference to base.
+type overlayFS struct {
+	files fstest.MapFS
+	base  fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if _, ok := o.files[name]; ok {
+		return o.files.Open(name)
+	}
+	return o.base.Open(name)
+}
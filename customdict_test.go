@@ -0,0 +1,71 @@
+package opencc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithCustomDict(t *testing.T) {
+	dict := "# brand names\n苹果\t蘋菓\r\n\n简体字\t简體字\n苹果\t忽略\n"
+	converter, err := NewConverter("s2t.json", WithCustomDict(strings.NewReader(dict)))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"吃苹果", "喫蘋菓"},
+		{"这是简体字", "這是简體字"},
+		{"汉字", "漢字"},
+	}
+	for _, tt := range tests {
+		got, err := converter.Convert(tt.input)
+		if err != nil {
+			t.Fatalf("Convert(%q) error = %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("Convert(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	// The dictionary is read once and reused by clones
+	clone, err := converter.Clone()
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	defer clone.Close()
+	if got, err := clone.Convert("吃苹果"); err != nil || got != "喫蘋菓" {
+		t.Errorf("Convert() on clone = %q, %v, want %q", got, err, "喫蘋菓")
+	}
+}
+
+func TestWithCustomDictInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		dict    string
+		wantErr error
+	}{
+		{"NoTab", "s2t.json", "苹果 蘋果\n", nil},
+		{"NoValue", "s2t.json", "苹果\t\n", nil},
+		{"InvalidUTF8", "s2t.json", "苹果\t\xff\n", nil},
+		{"MissingConfig", "does-not-exist.json", "苹果\t蘋果\n", ErrConfigNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(tt.config, WithCustomDict(strings.NewReader(tt.dict)))
+			if err == nil {
+				converter.Close()
+				t.Fatal("NewConverter() error = nil, want non-nil")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewConverter() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
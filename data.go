@@ -0,0 +1,128 @@
+package opencc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+	"testing/fstest"
+)
+
+// The embedded configurations and dictionaries are split by build tag so
+// binaries can leave out conversions they don't need:
+//
+//   - noopenccjp leaves out the Japanese Shinjitai configurations
+//     (t2jp.json, jp2t.json) and their dictionaries.
+//   - openccminimal only embeds s2t.json and t2s.json.
+//
+// ListConfigs reports the configurations embedded in the build.
+
+//go:embed data/s2t.json data/t2s.json data/ST*.ocd2.gz data/TS*.ocd2.gz
+var coreFS embed.FS
+
+// dataFS holds the embedded data under data/. Dictionaries are stored
+// gzip-compressed, as *.ocd2.gz.
+var dataFS = mergedFS{coreFS, regionalFS, jpFS}
+
+// dataSubFS returns the embedded data directory, with the dictionaries
+// decompressed, as the filesystem root. It is decompressed into memory once
+// and shared by all converters.
+var dataSubFS = sync.OnceValues(func() (fs.FS, error) {
+	return gunzipFS(dataFS, "data")
+})
+
+// gunzipFS reads the files in dir of fsys into memory, decompressing
+// *.gz files and dropping their extension.
+func gunzipFS(fsys fs.FS, dir string) (fs.FS, error) {
+	files := make(fstest.MapFS)
+	err := fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(name, ".gz") {
+			if data, err = gunzip(data); err != nil {
+				return fmt.Errorf("decompress %s: %w", name, err)
+			}
+			name = strings.TrimSuffix(name, ".gz")
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, dir), "/")
+		files[rel] = &fstest.MapFile{Data: data, Mode: 0o444}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
+
+// mergedFS combines filesystems, with the first holding a file taking
+// precedence. Directories are merged.
+type mergedFS []fs.FS
+
+func (m mergedFS) Open(name string) (fs.File, error) {
+	for _, fsys := range m {
+		if f, err := fsys.Open(name); err == nil {
+			return f, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (m mergedFS) ReadFile(name string) ([]byte, error) {
+	for _, fsys := range m {
+		if data, err := fs.ReadFile(fsys, name); err == nil {
+			return data, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (m mergedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	found := false
+	for _, fsys := range m {
+		dir, err := fs.ReadDir(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, e := range dir {
+			if !slices.ContainsFunc(entries, func(d fs.DirEntry) bool { return d.Name() == e.Name() }) {
+				entries = append(entries, e)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
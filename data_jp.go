@@ -0,0 +1,10 @@
+//go:build !noopenccjp && !openccminimal
+
+package opencc
+
+import "embed"
+
+// Japanese Shinjitai configurations
+//
+//go:embed data/t2jp.json data/jp2t.json data/JP*.ocd2.gz
+var jpFS embed.FS
@@ -0,0 +1,10 @@
+//go:build noopenccjp || openccminimal
+
+package opencc
+
+import "embed"
+
+var jpFS embed.FS
+
+// jpPools is empty, as the Japanese helpers are left out of this build.
+var jpPools []*ConverterPool
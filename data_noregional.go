@@ -0,0 +1,11 @@
+//go:build openccminimal
+
+package opencc
+
+import "embed"
+
+var regionalFS embed.FS
+
+// regionalPools is empty, as the Taiwan and Hong Kong helpers are left
+// out of this build.
+var regionalPools []*ConverterPool
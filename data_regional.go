@@ -0,0 +1,12 @@
+//go:build !openccminimal
+
+package opencc
+
+import "embed"
+
+// Taiwan and Hong Kong configurations
+//
+//go:embed data/s2tw.json data/tw2s.json data/s2twp.json data/tw2sp.json
+//go:embed data/s2hk.json data/hk2s.json data/t2tw.json data/tw2t.json
+//go:embed data/t2hk.json data/hk2t.json data/TW*.ocd2.gz data/HK*.ocd2.gz
+var regionalFS embed.FS
@@ -0,0 +1,118 @@
+package opencc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedConfigsComplete(t *testing.T) {
+	configs, err := ListConfigs()
+	if err != nil {
+		t.Fatalf("ListConfigs() error = %v", err)
+	}
+
+	// Every config embedded in this build must have its dictionaries
+	for _, config := range configs {
+		converter, err := NewConverter(config)
+		if err != nil {
+			t.Errorf("NewConverter(%q) error = %v", config, err)
+			continue
+		}
+		converter.Close()
+	}
+}
+
+func TestMergedFS(t *testing.T) {
+	m := mergedFS{
+		fstest.MapFS{"data/a.json": {Data: []byte("a")}, "data/b.json": {Data: []byte("b1")}},
+		fstest.MapFS{},
+		fstest.MapFS{"data/b.json": {Data: []byte("b2")}, "data/c.json": {Data: []byte("c")}},
+	}
+
+	data, err := fs.ReadFile(m, "data/b.json")
+	if err != nil || string(data) != "b1" {
+		t.Errorf("ReadFile() = %q, %v, want %q", data, err, "b1")
+	}
+	if _, err := m.Open("data/missing.json"); err == nil {
+		t.Error("Open() of a missing file error = nil")
+	}
+
+	sub, err := fs.Sub(m, "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := fs.ReadDir(sub, ".")
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"a.json", "b.json", "c.json"}; !slices.Equal(names, want) {
+		t.Errorf("ReadDir() = %v, want %v", names, want)
+	}
+}
+
+// bundled reports whether config is embedded in this build, which build
+// tags such as openccminimal can change.
+func bundled(t *testing.T, config string) bool {
+	t.Helper()
+	configs, err := ListConfigs()
+	if err != nil {
+		t.Fatalf("ListConfigs() error = %v", err)
+	}
+	return slices.Contains(configs, configName(config))
+}
+
+// requireConfigs skips the test unless every one of configs is embedded in
+// this build.
+func requireConfigs(t *testing.T, configs ...string) {
+	t.Helper()
+	for _, config := range configs {
+		if !bundled(t, config) {
+			t.Skipf("%s is not embedded in this build", config)
+		}
+	}
+}
+
+// readEmbedded reads name from the decompressed embedded data.
+func readEmbedded(name string) ([]byte, error) {
+	root, err := dataSubFS()
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(root, name)
+}
+
+func TestGunzipFS(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("dictionary"))
+	zw.Close()
+
+	fsys := fstest.MapFS{
+		"data/a.ocd2.gz": {Data: buf.Bytes()},
+		"data/a.json":    {Data: []byte("config")},
+		"data/bad.gz":    {Data: []byte("not gzip")},
+	}
+	if _, err := gunzipFS(fsys, "data"); err == nil {
+		t.Error("gunzipFS() error = nil for a corrupt file")
+	}
+
+	delete(fsys, "data/bad.gz")
+	root, err := gunzipFS(fsys, "data")
+	if err != nil {
+		t.Fatalf("gunzipFS() error = %v", err)
+	}
+	for name, want := range map[string]string{"a.ocd2": "dictionary", "a.json": "config"} {
+		data, err := fs.ReadFile(root, name)
+		if err != nil || string(data) != want {
+			t.Errorf("ReadFile(%q) = %q, %v, want %q", name, data, err, want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package opencc
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Variant is the script variant of a piece of Chinese text.
+type Variant int
+
+const (
+	VariantUnknown     Variant = iota // no characters specific to either variant
+	VariantSimplified                 // Simplified Chinese
+	VariantTraditional                // Traditional Chinese
+	VariantMixed                      // a mix of Simplified and Traditional characters
+)
+
+func (v Variant) String() string {
+	switch v {
+	case VariantSimplified:
+		return "Simplified"
+	case VariantTraditional:
+		return "Traditional"
+	case VariantMixed:
+		return "Mixed"
+	default:
+		return "Unknown"
+	}
+}
+
+// detectThreshold is the share of variant-specific characters one variant
+// needs for text not to be reported as VariantMixed.
+const detectThreshold = 0.8
+
+// DetectVariant guesses whether text is written in Simplified or
+// Traditional Chinese. See DetectVariantConfidence.
+func DetectVariant(text string) (Variant, error) {
+	v, _, err := DetectVariantConfidence(text)
+	return v, err
+}
+
+// DetectVariantConfidence guesses whether text is written in Simplified or
+// Traditional Chinese, along with a confidence between 0 and 1.
+//
+// Each distinct Han character is converted on its own with s2t and t2s.
+// Characters changed only by s2t are counted as Simplified, characters
+// changed only by t2s as Traditional, and characters common to both
+// variants are ignored. The confidence is the share of the counted
+// characters belonging to the detected variant; for VariantMixed it is how
+// evenly the two are balanced. Text without any variant-specific characters
+// is VariantUnknown with a confidence of 0.
+func DetectVariantConfidence(text string) (Variant, float64, error) {
+	freq := make(map[rune]int)
+	var chars []rune
+	for _, r := range text {
+		if !unicode.Is(unicode.Han, r) {
+			continue
+		}
+		if freq[r] == 0 {
+			chars = append(chars, r)
+		}
+		freq[r]++
+	}
+	if len(chars) == 0 {
+		return VariantUnknown, 0, nil
+	}
+
+	variants, err := classifyRunes(chars)
+	if err != nil {
+		return VariantUnknown, 0, err
+	}
+
+	var simplified, traditional int
+	for i, r := range chars {
+		switch variants[i] {
+		case VariantSimplified:
+			simplified += freq[r]
+		case VariantTraditional:
+			traditional += freq[r]
+		}
+	}
+
+	total := simplified + traditional
+	if total == 0 {
+		return VariantUnknown, 0, nil
+	}
+	share := float64(max(simplified, traditional)) / float64(total)
+	switch {
+	case share < detectThreshold:
+		return VariantMixed, 2 * (1 - share), nil
+	case simplified > traditional:
+		return VariantSimplified, share, nil
+	default:
+		return VariantTraditional, share, nil
+	}
+}
+
+// classifyRunes reports which variant each of chars is specific to.
+// Characters changed only by s2t are Simplified, characters changed only by
+// t2s are Traditional, and the others are VariantUnknown.
+func classifyRunes(chars []rune) ([]Variant, error) {
+	toTrad, err := convertRunes(s2tPool, chars)
+	if err != nil {
+		return nil, err
+	}
+	toSimp, err := convertRunes(t2sPool, chars)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := make([]Variant, len(chars))
+	for i, r := range chars {
+		s2t, t2s := toTrad[i] != string(r), toSimp[i] != string(r)
+		switch {
+		case s2t && !t2s:
+			variants[i] = VariantSimplified
+		case t2s && !s2t:
+			variants[i] = VariantTraditional
+		}
+	}
+	return variants, nil
+}
+
+// convertRunes converts each of chars on its own, so that phrases don't
+// affect the result, using a single call to the converter.
+func convertRunes(p *ConverterPool, chars []rune) ([]string, error) {
+	var sb strings.Builder
+	for i, r := range chars {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteRune(r)
+	}
+
+	result, err := p.Convert(sb.String())
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(result, "\n")
+	if len(lines) != len(chars) {
+		return nil, fmt.Errorf("convert characters: got %d results for %d characters", len(lines), len(chars))
+	}
+	return lines, nil
+}
@@ -0,0 +1,43 @@
+package opencc
+
+import "testing"
+
+func TestDetectVariant(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Variant
+	}{
+		{"Simplified", "这是一个简体中文的测试，汉字转换。", VariantSimplified},
+		{"Traditional", "這是一個繁體中文的測試，漢字轉換。", VariantTraditional},
+		{"Mixed", "简体字和繁體字", VariantMixed},
+		{"Common", "中文大人", VariantUnknown},
+		{"Latin", "Hello, world!", VariantUnknown},
+		{"Empty", "", VariantUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, confidence, err := DetectVariantConfidence(tt.input)
+			if err != nil {
+				t.Fatalf("DetectVariantConfidence() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectVariantConfidence() = %v, want %v", got, tt.want)
+			}
+			if confidence < 0 || confidence > 1 {
+				t.Errorf("confidence = %v, want it within [0, 1]", confidence)
+			}
+			if tt.want == VariantUnknown && confidence != 0 {
+				t.Errorf("confidence = %v, want 0", confidence)
+			}
+			if (tt.want == VariantSimplified || tt.want == VariantTraditional) && confidence < detectThreshold {
+				t.Errorf("confidence = %v, want at least %v", confidence, detectThreshold)
+			}
+
+			if v, err := DetectVariant(tt.input); err != nil || v != got {
+				t.Errorf("DetectVariant() = %v, %v, want %v", v, err, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package opencc
+
+import (
+	"fmt"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// ConvertGBKS2T decodes GBK or GB18030 encoded Simplified Chinese and
+// converts it to Traditional Chinese.
+func ConvertGBKS2T(input []byte) (string, error) {
+	return decodeConvert(simplifiedchinese.GB18030, "GB18030", s2tPool, input)
+}
+
+// ConvertBig5T2S decodes Big5 encoded Traditional Chinese and converts it to
+// Simplified Chinese.
+func ConvertBig5T2S(input []byte) (string, error) {
+	return decodeConvert(traditionalchinese.Big5, "Big5", t2sPool, input)
+}
+
+// ConvertS2TBig5 converts Simplified Chinese to Traditional Chinese and
+// encodes the result as Big5. Characters Big5 can't represent are reported
+// as an error.
+func ConvertS2TBig5(input string) ([]byte, error) {
+	return convertEncode(traditionalchinese.Big5, "Big5", s2tPool, input)
+}
+
+// ConvertT2SGBK converts Traditional Chinese to Simplified Chinese and
+// encodes the result as GBK. Characters GBK can't represent, such as those
+// only GB18030 encodes, are reported as an error naming the first of them.
+func ConvertT2SGBK(input string) ([]byte, error) {
+	return convertEncode(simplifiedchinese.GBK, "GBK", t2sPool, input)
+}
+
+// decodeConvert decodes input from enc to UTF-8 and converts it with p.
+func decodeConvert(enc encoding.Encoding, name string, p *ConverterPool, input []byte) (string, error) {
+	decoded, err := enc.NewDecoder().Bytes(input)
+	if err != nil {
+		return "", fmt.Errorf("decode %s: %w", name, err)
+	}
+	return convertPooled(p, string(decoded))
+}
+
+// convertEncode converts input with p and encodes the result with enc.
+func convertEncode(enc encoding.Encoding, name string, p *ConverterPool, input string) ([]byte, error) {
+	result, err := convertPooled(p, input)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := enc.NewEncoder().Bytes([]byte(result))
+	if err != nil {
+		if r, ok := unencodable(enc, result); ok {
+			return nil, fmt.Errorf("encode %s: character %q (%U): %w", name, r, r, err)
+		}
+		return nil, fmt.Errorf("encode %s: %w", name, err)
+	}
+	return encoded, nil
+}
+
+// unencodable returns the first rune of s that enc can't encode.
+func unencodable(enc encoding.Encoding, s string) (rune, bool) {
+	e := enc.NewEncoder()
+	for _, r := range s {
+		if _, err := e.String(string(r)); err != nil {
+			return r, true
+		}
+	}
+	return 0, false
+}
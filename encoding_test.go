@@ -0,0 +1,57 @@
+package opencc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+func TestConvertEncodings(t *testing.T) {
+	gbk, _ := simplifiedchinese.GBK.NewEncoder().String("简体中文")
+	big5, _ := traditionalchinese.Big5.NewEncoder().String("繁體中文")
+
+	got, err := ConvertGBKS2T([]byte(gbk))
+	if err != nil {
+		t.Fatalf("ConvertGBKS2T() error = %v", err)
+	}
+	if want := "簡體中文"; got != want {
+		t.Errorf("ConvertGBKS2T() = %q, want %q", got, want)
+	}
+
+	got, err = ConvertBig5T2S([]byte(big5))
+	if err != nil {
+		t.Fatalf("ConvertBig5T2S() error = %v", err)
+	}
+	if want := "繁体中文"; got != want {
+		t.Errorf("ConvertBig5T2S() = %q, want %q", got, want)
+	}
+
+	encoded, err := ConvertS2TBig5("繁体中文")
+	if err != nil {
+		t.Fatalf("ConvertS2TBig5() error = %v", err)
+	}
+	if !bytes.Equal(encoded, []byte(big5)) {
+		t.Errorf("ConvertS2TBig5() = %x, want %x", encoded, big5)
+	}
+
+	encoded, err = ConvertT2SGBK("簡體中文")
+	if err != nil {
+		t.Fatalf("ConvertT2SGBK() error = %v", err)
+	}
+	if !bytes.Equal(encoded, []byte(gbk)) {
+		t.Errorf("ConvertT2SGBK() = %x, want %x", encoded, gbk)
+	}
+
+	if _, err := ConvertS2TBig5("😀"); err == nil {
+		t.Error("ConvertS2TBig5() error = nil for a character Big5 can't encode")
+	}
+
+	// GB18030 encodes U+20000, GBK doesn't
+	_, err = ConvertT2SGBK("中文𠀀")
+	if want := "U+20000"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("ConvertT2SGBK() error = %v, want it to name %s", err, want)
+	}
+}
@@ -0,0 +1,433 @@
+package opencc
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// Engine owns a WASM runtime and the OpenCC binary compiled for it.
+// Converters created from an engine are instantiated from its compiled
+// module, so engines isolate converters that need a different memory limit
+// or compilation cache from the rest of the process. The package-level
+// functions use a default engine configured with SetCacheDir and
+// SetMemoryLimitPages. An Engine is safe for concurrent use.
+type Engine struct {
+	mu    sync.Mutex // guards the fields below
+	state *runtimeState
+
+	cacheDir         string
+	memoryLimitPages uint32
+	binary           []byte // to compile, if not the embedded binary
+
+	created, closed uint64 // converters
+}
+
+// runtimeState is one initialization of an engine's runtime. Close replaces
+// it, so the next use initializes a fresh one.
+type runtimeState struct {
+	once sync.Once
+	err  error // of the initialization, returned to every caller
+
+	rt    wazero.Runtime
+	cm    wazero.CompiledModule
+	cache wazero.CompilationCache
+
+	mu       sync.Mutex                                  // guards customCM and closed
+	customCM map[[sha256.Size]byte]wazero.CompiledModule // by WithBinary
+	closed   bool
+}
+
+// errEngineClosed is returned to callers that were about to initialize a
+// runtime, or compile a binary in it, when the engine was closed.
+var errEngineClosed = errors.New("engine closed")
+
+// defaultEngine backs NewConverter and the package-level helpers.
+var defaultEngine = &Engine{cacheDir: os.Getenv("OPENCC_CACHE_DIR")}
+
+// EngineOption configures an Engine created by NewEngine.
+type EngineOption func(*Engine)
+
+// WithCacheDir caches the compiled WASM binary in dir between process
+// runs, like SetCacheDir does for the default engine.
+func WithCacheDir(dir string) EngineOption {
+	return func(e *Engine) {
+		e.cacheDir = dir
+	}
+}
+
+// WithMemoryLimitPages caps the linear memory of each of the engine's
+// converters at pages 64 KiB pages, like SetMemoryLimitPages does for the
+// default engine.
+func WithMemoryLimitPages(pages uint32) EngineOption {
+	return func(e *Engine) {
+		e.memoryLimitPages = pages
+	}
+}
+
+// NewEngine creates an engine with its own WASM runtime. The runtime is
+// initialized and the binary compiled on first use, or by Preload.
+func NewEngine(opts ...EngineOption) *Engine {
+	e := new(Engine)
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// NewConverter creates a converter for configFile in the engine, like the
+// package-level NewConverter.
+func (e *Engine) NewConverter(configFile string, opts ...Option) (*Converter, error) {
+	return e.NewConverterContext(context.Background(), configFile, opts...)
+}
+
+// NewConverterContext is like NewConverter but uses ctx to bound
+// compilation, instantiation and opening the configuration.
+func (e *Engine) NewConverterContext(ctx context.Context, configFile string, opts ...Option) (*Converter, error) {
+	return newConverter(ctx, e, configFile, opts)
+}
+
+// Preload initializes the engine's runtime and compiles the embedded binary
+// ahead of the first converter. See the package-level Preload.
+func (e *Engine) Preload(ctx context.Context) error {
+	_, err := e.runtime(ctx)
+	return err
+}
+
+// Close closes the engine's runtime along with every converter created
+// from it and releases the compiled modules. Converters created before
+// Close can no longer be used. Creating a converter afterwards initializes
+// the runtime again, which also retries an initialization that failed.
+func (e *Engine) Close(ctx context.Context) error {
+	e.mu.Lock()
+	st := e.state
+	e.state = nil
+	e.mu.Unlock()
+
+	if st == nil {
+		return nil
+	}
+	// Wait for an initialization in progress, or stop one from starting
+	st.once.Do(func() { st.err = errEngineClosed })
+	return st.close(ctx)
+}
+
+// ConverterStats counts the converters created in an engine.
+type ConverterStats struct {
+	Live    uint64 // created and not yet closed
+	Created uint64
+	Closed  uint64
+}
+
+// Stats returns counts of the converters created in the engine, including
+// those created by pools and clones. A Live count that keeps growing points
+// at converters that are never closed.
+func (e *Engine) Stats() ConverterStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return ConverterStats{Live: e.created - e.closed, Created: e.created, Closed: e.closed}
+}
+
+// countConverter records that a converter was created, or closed if
+// created is false.
+func (e *Engine) countConverter(created bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if created {
+		e.created++
+	} else {
+		e.closed++
+	}
+}
+
+// runtime returns the engine's runtime, initializing it the first time it
+// is used. Every caller gets the result of the same initialization, so a
+// failure is returned to all of them until the engine is closed, except
+// that an initialization stopped by a done context is tried again by the
+// next caller.
+func (e *Engine) runtime(ctx context.Context) (*runtimeState, error) {
+	for {
+		e.mu.Lock()
+		if e.state == nil {
+			e.state = new(runtimeState)
+		}
+		st, cacheDir, memoryLimitPages, wasm := e.state, e.cacheDir, e.memoryLimitPages, e.binary
+		e.mu.Unlock()
+
+		if wasm == nil {
+			wasm = binary
+		}
+		st.once.Do(func() { st.err = st.init(ctx, wasm, cacheDir, memoryLimitPages) })
+		if st.err == nil || !isContextError(st.err) {
+			return st, st.err
+		}
+
+		e.mu.Lock()
+		if e.state == st {
+			e.state = nil
+		}
+		e.mu.Unlock()
+
+		// Another caller's context may have stopped the initialization
+		if ctx.Err() != nil {
+			return nil, st.err
+		}
+	}
+}
+
+// isContextError reports whether err was caused by a done context.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// close closes the runtime and compilation cache. The fields are left as
+// they are, since converters may still be created from st concurrently;
+// those get errors from the closed runtime instead.
+func (st *runtimeState) close(ctx context.Context) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.closed = true
+	var errs []error
+	if st.rt != nil {
+		errs = append(errs, st.rt.Close(ctx))
+	}
+	if st.cache != nil {
+		errs = append(errs, st.cache.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// init creates the runtime and compiles wasm. Only st.once calls it, so
+// nobody sees the runtime before it either fully succeeds or is closed
+// again.
+func (st *runtimeState) init(ctx context.Context, wasm []byte, cacheDir string, memoryLimitPages uint32) (err error) {
+	// Close modules whose call context is done so cancellation
+	// interrupts in-flight conversions
+	config := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if memoryLimitPages > 0 {
+		config = config.WithMemoryLimitPages(memoryLimitPages)
+	}
+	if cacheDir != "" {
+		if st.cache, err = wazero.NewCompilationCacheWithDir(cacheDir); err != nil {
+			return fmt.Errorf("open compilation cache: %w", err)
+		}
+		config = config.WithCompilationCache(st.cache)
+	}
+
+	st.rt = wazero.NewRuntimeWithConfig(context.Background(), config)
+	defer func() {
+		// Don't leave a half-initialized runtime behind. Nobody else sees
+		// st before st.once returns, so the fields can be reset too
+		if err != nil {
+			st.close(context.Background())
+			st.rt, st.cm, st.cache = nil, nil, nil
+		}
+	}()
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, st.rt); err != nil {
+		return fmt.Errorf("instantiate wasi: %w", err)
+	}
+
+	// Create env module for C++ runtime functions
+	envModuleBuilder := st.rt.NewHostModuleBuilder("env")
+
+	// C++ exception handling functions
+	envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+		// __cxa_allocate_exception - allocate memory for exception
+		size := uint32(stack[0])
+		malloc := mod.ExportedFunction("malloc")
+		ret, _ := malloc.Call(ctx, uint64(size))
+		stack[0] = ret[0]
+	}), []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}).Export("__cxa_allocate_exception")
+
+	envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+		// __cxa_throw - throw exception, try to get error info
+		exceptionPtr := uint32(stack[0])
+		exc := &cxxException{ptr: exceptionPtr, msg: readException(mod.Memory(), exceptionPtr)}
+		if exc.msg != "" {
+			getLogger().Warn("OpenCC exception thrown", "message", exc.msg)
+		}
+
+		// Exceptions can't unwind inside the WASM binary, so abort the
+		// call and let module.call report it
+		if m, ok := ctx.Value(moduleKey{}).(*module); ok {
+			m.exception = exc
+		}
+		panic(exc)
+	}), []api.ValueType{api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{}).Export("__cxa_throw")
+
+	envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+		// __cxa_free_exception - free exception memory
+		ptr := uint32(stack[0])
+		free := mod.ExportedFunction("free")
+		if _, err := free.Call(ctx, uint64(ptr)); err != nil {
+			getLogger().Warn("error freeing exception memory", "error", err)
+		}
+	}), []api.ValueType{api.ValueTypeI32}, []api.ValueType{}).Export("__cxa_free_exception")
+
+	// Personality function for exception handling
+	envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+		// Just return 0 to indicate we don't handle exceptions
+		stack[0] = 0
+	}), []api.ValueType{api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI64, api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}).Export("__gxx_personality_v0")
+
+	// Type info functions
+	envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+		// __cxa_begin_catch - begin catching exception
+		// Return the exception pointer as-is (pass-through)
+		// stack[0] already contains the input, no assignment needed
+	}), []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}).Export("__cxa_begin_catch")
+
+	envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+		// __cxa_end_catch - end catching exception (no-op)
+	}), []api.ValueType{}, []api.ValueType{}).Export("__cxa_end_catch")
+
+	if _, err := envModuleBuilder.Instantiate(ctx); err != nil {
+		return fmt.Errorf("instantiate env module: %w", err)
+	}
+
+	st.cm, err = compileContext(ctx, st.rt, wasm)
+	if err != nil {
+		return fmt.Errorf("compile module: %w", err)
+	}
+	return nil
+}
+
+// compileCustom returns the compiled module for the binary supplied with
+// WithBinary, compiling it the first time it is used.
+func (st *runtimeState) compileCustom(ctx context.Context, opts *options) (wazero.CompiledModule, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	// wazero doesn't check whether the runtime was closed before compiling
+	if st.closed {
+		return nil, fmt.Errorf("compile custom module: %w", errEngineClosed)
+	}
+	if compiled, ok := st.customCM[opts.binaryKey]; ok {
+		return compiled, nil
+	}
+
+	compiled, err := compileContext(ctx, st.rt, opts.binary)
+	if err != nil {
+		return nil, fmt.Errorf("compile custom module: %w", err)
+	}
+	if st.customCM == nil {
+		st.customCM = make(map[[sha256.Size]byte]wazero.CompiledModule)
+	}
+	st.customCM[opts.binaryKey] = compiled
+	return compiled, nil
+}
+
+// compileContext compiles wasm, returning ctx.Err() if ctx is done first.
+// wazero can't interrupt a compilation, so it carries on in the background
+// and its result is discarded.
+func compileContext(ctx context.Context, r wazero.Runtime, wasm []byte) (wazero.CompiledModule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if ctx.Done() == nil {
+		return r.CompileModule(ctx, wasm)
+	}
+
+	type result struct {
+		compiled wazero.CompiledModule
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		compiled, err := r.CompileModule(context.Background(), wasm)
+		done <- result{compiled, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.compiled, res.err
+	case <-ctx.Done():
+		go func() {
+			if res := <-done; res.err == nil {
+				res.compiled.Close(context.Background())
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// newModule instantiates a module for opts in the engine.
+func (e *Engine) newModule(ctx context.Context, opts *options) (*module, error) {
+	// Initialization happens once; the compiled module is immutable
+	// afterwards, so instances are created from it concurrently without
+	// holding any lock (see BenchmarkNewConverterParallel)
+	st, err := e.runtime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, compiled := st.rt, st.cm
+	if opts.binary != nil {
+		if compiled, err = st.compileCustom(ctx, opts); err != nil {
+			return nil, err
+		}
+		if missing := newCapabilities(compiled.ExportedFunctions()).Missing(); len(missing) > 0 {
+			return nil, fmt.Errorf("custom binary lacks %s: %w", strings.Join(missing, ", "), errors.ErrUnsupported)
+		}
+	}
+
+	// Configure module with embedded file system access unless the caller
+	// supplied their own
+	root, err := opts.root()
+	if err != nil {
+		return nil, fmt.Errorf("create data sub-filesystem: %w", err)
+	}
+
+	// Leave the module anonymous so several instances can be live at once
+	config := wazero.NewModuleConfig().
+		WithFS(root). // Mount data directory as root
+		WithArgs("opencc").
+		WithName("").
+		WithStdout(opts.stdout).
+		WithStderr(opts.stderr)
+	for _, f := range opts.moduleConfig {
+		config = f(config)
+	}
+
+	var mod api.Module
+	err = retry(ctx, opts.instantiateAttempts, opts.instantiateBackoff, retryableInstantiate, func() error {
+		mod, err = r.InstantiateModule(ctx, compiled, config)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("instantiate module: %w", err)
+	}
+
+	return &module{
+		mod:        mod,
+		convertLen: mod.ExportedFunction("opencc_convert_len") != nil,
+	}, nil
+}
+
+// retryableInstantiate reports whether instantiating a module may succeed
+// when tried again after failing with err. Linking the module and
+// initializing its memory fail the same way every time, as do a start
+// function that exits and a closed runtime, so only a start function that
+// trapped, such as when memory couldn't be grown under memory pressure, is
+// worth retrying.
+func retryableInstantiate(err error) bool {
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) {
+		return false
+	}
+	// wazero reports a failed start function as "start ... failed: ..." or
+	// "module[...] function[...] failed: ..." and has no error type for it
+	return strings.Contains(err.Error(), " failed: ")
+}
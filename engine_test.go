@@ -0,0 +1,296 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEngine(t *testing.T) {
+	// Room for little more than the dictionaries, unlike the default engine
+	engine := NewEngine(WithMemoryLimitPages(160))
+	defer engine.Close(context.Background())
+
+	if err := engine.Preload(context.Background()); err != nil {
+		t.Fatalf("Preload() error = %v", err)
+	}
+	limited, err := engine.NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer limited.Close()
+
+	unlimited, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer unlimited.Close()
+
+	input := strings.Repeat("简体字", 4<<20/9)
+	if _, err := limited.Convert(input); !errors.Is(err, ErrOutOfMemory) {
+		t.Errorf("Convert() in limited engine error = %v, want %v", err, ErrOutOfMemory)
+	}
+	if result, err := unlimited.Convert("简体字"); err != nil || result != "簡體字" {
+		t.Errorf("Convert() in default engine = %q, %v, want %q, nil", result, err, "簡體字")
+	}
+	if defaultEngine.memoryLimitPages != 0 {
+		t.Errorf("default engine memory limit = %d pages, want 0", defaultEngine.memoryLimitPages)
+	}
+
+	clone, err := limited.Clone()
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	defer clone.Close()
+	if _, err := clone.Convert(input); !errors.Is(err, ErrOutOfMemory) {
+		t.Errorf("Convert() in clone error = %v, want %v", err, ErrOutOfMemory)
+	}
+}
+
+func TestEngineClose(t *testing.T) {
+	engine := NewEngine()
+	converter, err := engine.NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	other, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer other.Close()
+
+	if err := engine.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := converter.Convert("简体字"); err == nil {
+		t.Error("Convert() after engine Close() error = nil, want error")
+	}
+
+	// Other engines are unaffected
+	if result, err := other.Convert("简体字"); err != nil || result != "簡體字" {
+		t.Errorf("Convert() in default engine = %q, %v, want %q, nil", result, err, "簡體字")
+	}
+
+	// The engine initializes again on next use
+	again, err := engine.NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() after Close() error = %v", err)
+	}
+	defer again.Close()
+	if result, err := again.Convert("简体字"); err != nil || result != "簡體字" {
+		t.Errorf("Convert() = %q, %v, want %q, nil", result, err, "簡體字")
+	}
+	engine.Close(context.Background())
+}
+
+// stubWasm is a module with a bump allocator exporting the functions every
+// converter needs, whose opencc_convert returns its input unchanged. Unlike
+// the embedded binary it never calls into WASI, so it is instantiated and
+// called without touching the module's filesystem.
+var stubWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32) -> i32, (i32) -> (), (i32, i32) -> i32
+	0x01, 0x10, 0x03,
+	0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x01, 0x7f, 0x00,
+	0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7f,
+	// Functions: malloc, free, opencc_open, opencc_convert,
+	// opencc_convert_free, opencc_close
+	0x03, 0x07, 0x06, 0x00, 0x01, 0x00, 0x02, 0x01, 0x00,
+	// Memory: one page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Globals: the next free address, starting at 1024
+	0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b,
+	// Exports: memory and the functions
+	0x07, 0x5e, 0x07,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x06, 'm', 'a', 'l', 'l', 'o', 'c', 0x00, 0x00,
+	0x04, 'f', 'r', 'e', 'e', 0x00, 0x01,
+	0x0b, 'o', 'p', 'e', 'n', 'c', 'c', '_', 'o', 'p', 'e', 'n', 0x00, 0x02,
+	0x0e, 'o', 'p', 'e', 'n', 'c', 'c', '_', 'c', 'o', 'n', 'v', 'e', 'r', 't', 0x00, 0x03,
+	0x13, 'o', 'p', 'e', 'n', 'c', 'c', '_', 'c', 'o', 'n', 'v', 'e', 'r', 't', '_', 'f', 'r', 'e', 'e', 0x00, 0x04,
+	0x0c, 'o', 'p', 'e', 'n', 'c', 'c', '_', 'c', 'l', 'o', 's', 'e', 0x00, 0x05,
+	// Code
+	0x0a, 0x22, 0x06,
+	// malloc: return next, next += size
+	0x0b, 0x00, 0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x0b,
+	// free: nothing
+	0x02, 0x00, 0x0b,
+	// opencc_open: return handle 1
+	0x04, 0x00, 0x41, 0x01, 0x0b,
+	// opencc_convert: return input
+	0x04, 0x00, 0x20, 0x01, 0x0b,
+	// opencc_convert_free: nothing
+	0x02, 0x00, 0x0b,
+	// opencc_close: return 0
+	0x04, 0x00, 0x41, 0x00, 0x0b,
+}
+
+func TestEngineCloseConcurrent(t *testing.T) {
+	// The cache saves compiling the binary again after every Close
+	engine := NewEngine(WithCacheDir(t.TempDir()))
+	defer engine.Close(context.Background())
+
+	if err := engine.Preload(context.Background()); err != nil {
+		t.Fatalf("Preload() error = %v", err)
+	}
+
+	// Converters created while the engine closes either work or fail with
+	// an error, never panic or race (go test -race). The stub binary keeps
+	// wazero from closing a module's files while it is reading them
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for range 64 {
+				c, err := engine.NewConverter("s2t.json", WithBinary(stubWasm))
+				if err != nil {
+					continue
+				}
+				c.Convert("简体字")
+				c.Close()
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				engine.Close(context.Background())
+			}
+		}
+	}()
+	wg.Wait()
+	close(done)
+
+	c, err := engine.NewConverter("s2t.json", WithBinary(stubWasm))
+	if err != nil {
+		t.Fatalf("NewConverter() after Close() error = %v", err)
+	}
+	defer c.Close()
+	if result, err := c.Convert("简体字"); err != nil || result != "简体字" {
+		t.Errorf("Convert() = %q, %v, want %q, nil", result, err, "简体字")
+	}
+}
+
+func TestEngineConcurrentInit(t *testing.T) {
+	engine := NewEngine()
+	defer engine.Close(context.Background())
+
+	states := make([]*runtimeState, 8)
+	var wg sync.WaitGroup
+	for i := range states {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			st, err := engine.runtime(context.Background())
+			if err != nil {
+				t.Errorf("runtime() error = %v", err)
+			}
+			states[i] = st
+		}()
+	}
+	wg.Wait()
+
+	for i, st := range states {
+		if st != states[0] || st.rt == nil || st.cm == nil {
+			t.Errorf("caller %d got runtime %p, want the initialized runtime %p", i, st, states[0])
+		}
+	}
+}
+
+func TestEngineInitFailure(t *testing.T) {
+	engine := NewEngine()
+	engine.binary = []byte("not a WASM binary")
+	defer engine.Close(context.Background())
+
+	_, err := engine.NewConverter("s2t.json")
+	if err == nil || !strings.Contains(err.Error(), "compile module") {
+		t.Fatalf("NewConverter() error = %v, want a compile error", err)
+	}
+
+	st := engine.state
+	if st.rt != nil || st.cm != nil {
+		t.Error("failed initialization left a runtime behind")
+	}
+
+	// Every caller gets the same error without initializing again
+	_, again := engine.NewConverter("s2t.json")
+	if !errors.Is(again, st.err) || engine.state != st {
+		t.Errorf("NewConverter() again error = %v, want the cached %v", again, st.err)
+	}
+	if err := engine.Preload(context.Background()); err != st.err {
+		t.Errorf("Preload() error = %v, want the cached %v", err, st.err)
+	}
+
+	// Closing the engine lets the next use try again
+	if err := engine.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	engine.binary = nil
+	converter, err := engine.NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() after Close() error = %v", err)
+	}
+	converter.Close()
+}
+
+func TestEngineStats(t *testing.T) {
+	engine := NewEngine()
+	defer engine.Close(context.Background())
+
+	var converters []*Converter
+	for range 3 {
+		c, err := engine.NewConverter("s2t.json")
+		if err != nil {
+			t.Fatalf("NewConverter() error = %v", err)
+		}
+		converters = append(converters, c)
+	}
+	clone, err := converters[0].Clone()
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	if _, err := engine.NewConverter("missing.json"); err == nil {
+		t.Fatal("NewConverter() error = nil, want error")
+	}
+
+	converters[1].Close()
+	converters[1].Close()
+	clone.Close()
+	if err := converters[2].Recycle(); err != nil {
+		t.Fatalf("Recycle() error = %v", err)
+	}
+
+	want := ConverterStats{Live: 2, Created: 4, Closed: 2}
+	if got := engine.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	for _, c := range converters {
+		c.Close()
+	}
+
+	// The default engine counts separately
+	before := Stats()
+	c, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	if got := Stats(); got.Created != before.Created+1 || got.Live != before.Live+1 {
+		t.Errorf("Stats() after NewConverter() = %+v, want one more than %+v", got, before)
+	}
+	c.Close()
+	if got := Stats(); got.Closed != before.Closed+1 || got.Live != before.Live {
+		t.Errorf("Stats() after Close() = %+v, want one more closed than %+v", got, before)
+	}
+}
@@ -0,0 +1,106 @@
+package opencc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by this package wrap one of the sentinels below, so
+// callers can branch with errors.Is. Some sentinels refine others:
+//
+//	ErrInvalidConverter   the converter can't be used
+//	  ErrOpenFailed       OpenCC failed to open the configuration
+//	ErrConfigNotFound     the configuration file doesn't exist
+//	ErrConversionFailed   OpenCC failed to convert the input
+//	ErrOutOfMemory        WASM memory ran out while opening or converting
+//	ErrInvalidInput       the input can't be converted
+//	  ErrInputTooLarge    the input exceeds the converter's size limit
+//
+// A failed NewConverter wraps ErrConfigNotFound, ErrOpenFailed or
+// ErrOutOfMemory; methods of a closed converter return ErrInvalidConverter.
+// ErrConfigNotFound is checked before OpenCC runs and deliberately doesn't
+// match ErrInvalidConverter, so a typo can be told apart from a broken
+// configuration.
+// A failed conversion wraps ErrConversionFailed, ErrOutOfMemory or
+// ErrInvalidInput.
+var (
+	ErrInvalidConverter error = &sentinelError{msg: "invalid converter"}
+	ErrOpenFailed       error = &sentinelError{msg: "open failed", parent: ErrInvalidConverter}
+	ErrConfigNotFound   error = &sentinelError{msg: "config not found"}
+	ErrConversionFailed error = &sentinelError{msg: "conversion failed"}
+	ErrOutOfMemory      error = &sentinelError{msg: "out of memory"}
+	ErrInvalidInput     error = &sentinelError{msg: "invalid input"}
+	ErrInputTooLarge    error = &sentinelError{msg: "input too large", parent: ErrInvalidInput}
+)
+
+// sentinelError is a sentinel that also matches the broader sentinel it
+// refines, without repeating its message.
+type sentinelError struct {
+	msg    string
+	parent error
+}
+
+func (e *sentinelError) Error() string {
+	return e.msg
+}
+
+func (e *sentinelError) Unwrap() error {
+	return e.parent
+}
+
+// errNullResult reports that an export returning a string returned a null
+// pointer, which is how OpenCC signals a failed conversion. An empty result
+// is a valid pointer to an empty string.
+var errNullResult = errors.New("null result")
+
+// ConversionError describes a failure reported by OpenCC while opening a
+// configuration or converting text. Err is the sentinel the failure maps to,
+// so errors.Is(err, ErrOpenFailed) keeps working.
+type ConversionError struct {
+	Op      string // "open" or "convert"
+	Config  string // configuration file in use
+	Message string // message of the C++ exception, if one was thrown
+	Err     error
+}
+
+func (e *ConversionError) Error() string {
+	msg := e.Op
+	if e.Config != "" {
+		msg += " " + e.Config
+	}
+	msg += ": " + e.Err.Error()
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// InputError reports input that can't be converted, such as text with an
+// embedded NUL byte, which OpenCC would silently treat as the end of the
+// input. It unwraps to ErrInvalidInput.
+type InputError struct {
+	Offset int    // byte offset of the offending input
+	Reason string // what is wrong with it
+}
+
+func (e *InputError) Error() string {
+	return fmt.Sprintf("%v: %s at offset %d", ErrInvalidInput, e.Reason, e.Offset)
+}
+
+func (e *InputError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// exceptionError returns a ConversionError for err if it was caused by a C++
+// exception thrown inside the WASM binary, or nil otherwise.
+func exceptionError(op, configFile string, sentinel, err error) error {
+	var exc *cxxException
+	if !errors.As(err, &exc) {
+		return nil
+	}
+	return &ConversionError{Op: op, Config: configFile, Message: exc.msg, Err: sentinel}
+}
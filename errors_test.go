@@ -0,0 +1,299 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// missingDictFS returns a filesystem holding s2t.json without the
+// dictionaries it references, so opening it throws inside OpenCC.
+func missingDictFS(t testing.TB) fs.FS {
+	t.Helper()
+
+	config, err := readEmbedded("s2t.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fstest.MapFS{"s2t.json": &fstest.MapFile{Data: config}}
+}
+
+func TestConversionError(t *testing.T) {
+	converter, err := NewConverterFromFS(missingDictFS(t), "s2t.json")
+	if err == nil {
+		converter.Close()
+		t.Fatal("NewConverter() error = nil, want non-nil")
+	}
+
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Fatalf("NewConverter() error = %T, want *ConversionError", err)
+	}
+	if convErr.Op != "open" {
+		t.Errorf("Op = %q, want %q", convErr.Op, "open")
+	}
+	if convErr.Config != "s2t.json" {
+		t.Errorf("Config = %q, want %q", convErr.Config, "s2t.json")
+	}
+	if !strings.Contains(convErr.Message, "STPhrases.ocd2") {
+		t.Errorf("Message = %q, want it to mention the missing dictionary", convErr.Message)
+	}
+	if !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("errors.Is(%v, ErrInvalidConverter) = false, want true", err)
+	}
+	if !errors.Is(err, ErrOpenFailed) {
+		t.Errorf("errors.Is(%v, ErrOpenFailed) = false, want true", err)
+	}
+	if errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("errors.Is(%v, ErrConfigNotFound) = true, want false", err)
+	}
+}
+
+func TestSentinelHierarchy(t *testing.T) {
+	sentinels := []error{
+		ErrInvalidConverter, ErrOpenFailed, ErrConfigNotFound,
+		ErrConversionFailed, ErrOutOfMemory, ErrInvalidInput, ErrInputTooLarge,
+	}
+	parents := map[error][]error{
+		ErrOpenFailed:    {ErrInvalidConverter},
+		ErrInputTooLarge: {ErrInvalidInput},
+	}
+
+	for _, err := range sentinels {
+		for _, target := range sentinels {
+			want := err == target
+			for _, parent := range parents[err] {
+				want = want || parent == target
+			}
+			if got := errors.Is(err, target); got != want {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", err, target, got, want)
+			}
+		}
+	}
+
+	if got := ErrConfigNotFound.Error(); got != "config not found" {
+		t.Errorf("ErrConfigNotFound.Error() = %q, want %q", got, "config not found")
+	}
+}
+
+func TestSentinelsFromAPI(t *testing.T) {
+	closed, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	closed.Close()
+
+	tooLarge, err := NewConverter("s2t.json", WithMaxInputSize(4))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer tooLarge.Close()
+
+	tests := []struct {
+		name string
+		err  func() error
+		want []error
+	}{
+		{"missing config", func() error {
+			_, err := NewConverter("missing.json")
+			return err
+		}, []error{ErrConfigNotFound}},
+		{"missing dictionary", func() error {
+			_, err := NewConverterFromFS(missingDictFS(t), "s2t.json")
+			return err
+		}, []error{ErrOpenFailed, ErrInvalidConverter}},
+		{"closed converter", func() error {
+			_, err := closed.Convert("简体")
+			return err
+		}, []error{ErrInvalidConverter}},
+		{"invalid UTF-8", func() error {
+			_, err := tooLarge.Convert("\xff")
+			return err
+		}, []error{ErrInvalidInput}},
+		{"too large", func() error {
+			_, err := tooLarge.Convert("简体字")
+			return err
+		}, []error{ErrInputTooLarge, ErrInvalidInput}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err()
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("errors.Is(%v, %v) = false, want true", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigNotFound(t *testing.T) {
+	for _, config := range []string{"does-not-exist.json", "../s2t.json", "/missing.json", ""} {
+		converter, err := NewConverter(config)
+		if err == nil {
+			converter.Close()
+			t.Errorf("NewConverter(%q) error = nil, want non-nil", config)
+			continue
+		}
+		if !errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("NewConverter(%q) error = %v, want %v", config, err, ErrConfigNotFound)
+		}
+		if errors.Is(err, ErrInvalidConverter) {
+			t.Errorf("NewConverter(%q) error = %v, want it not to match %v", config, err, ErrInvalidConverter)
+		}
+	}
+}
+
+func TestConversionErrorString(t *testing.T) {
+	err := &ConversionError{Op: "convert", Config: "s2t.json", Message: "boom", Err: ErrConversionFailed}
+	want := "convert s2t.json: conversion failed: boom"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, ErrConversionFailed) {
+		t.Errorf("errors.Is(%v, ErrConversionFailed) = false, want true", err)
+	}
+}
+
+func TestOutOfMemory(t *testing.T) {
+	// Restart the runtime with room for little more than the dictionaries
+	Shutdown(context.Background())
+	SetMemoryLimitPages(160)
+	defer func() {
+		SetMemoryLimitPages(0)
+		Shutdown(context.Background())
+	}()
+
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	input := strings.Repeat("简体字", 4<<20/9)
+	if _, err := converter.Convert(input); !errors.Is(err, ErrOutOfMemory) {
+		t.Errorf("Convert() error = %v, want %v", err, ErrOutOfMemory)
+	}
+
+	// The converter stays usable for inputs that fit
+	if result, err := converter.Convert("简体字"); err != nil || result != "簡體字" {
+		t.Errorf("Convert() = %q, %v, want %q, nil", result, err, "簡體字")
+	}
+}
+
+func TestOutOfMemoryDuringConvert(t *testing.T) {
+	// The input fits, but converting it takes more than the dictionaries
+	// leave room for
+	engine := NewEngine(WithMemoryLimitPages(160))
+	defer engine.Close(context.Background())
+
+	converter, err := engine.NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	mod := converter.mod
+	input := strings.Repeat("简体字", 512<<10/9)
+	_, err = converter.Convert(input)
+	if !errors.Is(err, ErrOutOfMemory) {
+		t.Fatalf("Convert() error = %v, want %v", err, ErrOutOfMemory)
+	}
+	if want := fmt.Sprintf("input of %d bytes", len(input)); !strings.Contains(err.Error(), want) {
+		t.Errorf("Convert() error = %q, want it to contain %q", err, want)
+	}
+
+	// OpenCC trapped halfway through, so the instance was replaced
+	if converter.mod == mod {
+		t.Error("module instance wasn't recycled after the trap")
+	}
+	if result, err := converter.Convert("简体字"); err != nil || result != "簡體字" {
+		t.Errorf("Convert() = %q, %v, want %q, nil", result, err, "簡體字")
+	}
+}
+
+func TestInvalidInputNUL(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	_, err = converter.Convert("简体\x00字")
+	var inputErr *InputError
+	if !errors.As(err, &inputErr) {
+		t.Fatalf("Convert() error = %v, want *InputError", err)
+	}
+	if inputErr.Offset != 6 {
+		t.Errorf("Offset = %d, want %d", inputErr.Offset, 6)
+	}
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("errors.Is(%v, ErrInvalidInput) = false, want true", err)
+	}
+
+	if _, err := converter.ConvertBytes([]byte("\x00")); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("ConvertBytes() error = %v, want %v", err, ErrInvalidInput)
+	}
+
+	input := strings.Repeat("a\n", StreamChunkSize) + "\x00"
+	err = converter.ConvertStream(strings.NewReader(input), io.Discard)
+	if !errors.As(err, &inputErr) {
+		t.Fatalf("ConvertStream() error = %v, want *InputError", err)
+	}
+	if inputErr.Offset != len(input)-1 {
+		t.Errorf("ConvertStream() Offset = %d, want %d", inputErr.Offset, len(input)-1)
+	}
+}
+
+func TestInvalidInputUTF8(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		input  string
+		offset int
+	}{
+		{"简体\xff字", 6},
+		{"abc\xe7\xae", 3},
+		{"\xe7\xae\x80\x80", 3},
+		{"a\xffb\x00", 1},
+		{"a\x00b\xff", 1},
+	}
+
+	for _, tt := range tests {
+		_, err := converter.ConvertBytes([]byte(tt.input))
+		var inputErr *InputError
+		if !errors.As(err, &inputErr) {
+			t.Errorf("ConvertBytes(%q) error = %v, want *InputError", tt.input, err)
+			continue
+		}
+		if inputErr.Offset != tt.offset {
+			t.Errorf("ConvertBytes(%q) Offset = %d, want %d", tt.input, inputErr.Offset, tt.offset)
+		}
+	}
+
+	if _, err := converter.Convert("简体\xff字"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Convert() error = %v, want %v", err, ErrInvalidInput)
+	}
+}
+
+func TestWithSkipValidation(t *testing.T) {
+	converter, err := NewConverter("s2t.json", WithSkipValidation())
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	if _, err := converter.Convert("简体\xff字"); errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Convert() error = %v, want no validation", err)
+	}
+}
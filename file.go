@@ -0,0 +1,72 @@
+package opencc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ConvertFile converts the text file at inPath and writes the result to
+// outPath, streaming it through ConvertStream so large files don't have to
+// fit in memory. Newlines and everything else OpenCC doesn't convert are
+// written back byte for byte, including a byte order mark at the start of
+// the file, with or without WithKeepBOM. The output is written to a
+// temporary file that replaces outPath once complete, so outPath may equal
+// inPath to convert a file in place, and a failed conversion leaves outPath
+// untouched.
+func (c *Converter) ConvertFile(inPath, outPath string) (err error) {
+	if c.IsClosed() {
+		return ErrInvalidConverter
+	}
+
+	in, err := os.Open(inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(outPath), "."+filepath.Base(outPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(out.Name())
+		}
+	}()
+
+	if err := out.Chmod(info.Mode().Perm()); err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		return err
+	}
+
+	// ConvertStream strips a leading byte order mark by default, so copy it
+	// over here and convert the rest
+	br := bufio.NewReader(in)
+	skipped := 0
+	if b, _ := br.Peek(len(bom)); string(b) == bom {
+		skipped, _ = br.Discard(len(bom))
+		if _, err := io.WriteString(out, bom); err != nil {
+			return err
+		}
+	}
+	if err := c.ConvertStream(br, out); err != nil {
+		var inputErr *InputError
+		if errors.As(err, &inputErr) {
+			inputErr.Offset += skipped
+		}
+		return fmt.Errorf("convert %s: %w", inPath, err)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), outPath)
+}
@@ -0,0 +1,113 @@
+package opencc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertFile(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	dir := t.TempDir()
+	input := "这是一个测试\r\n简体字  \n\n转换"
+	expected := "這是一個測試\r\n簡體字  \n\n轉換"
+
+	inPath := filepath.Join(dir, "in.txt")
+	if err := os.WriteFile(inPath, []byte(input), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(dir, "out.txt")
+	if err := converter.ConvertFile(inPath, outPath); err != nil {
+		t.Fatalf("ConvertFile() error = %v", err)
+	}
+	checkFile(t, outPath, expected)
+
+	// In place
+	if err := converter.ConvertFile(inPath, inPath); err != nil {
+		t.Fatalf("ConvertFile() in place error = %v", err)
+	}
+	checkFile(t, inPath, expected)
+
+	info, err := os.Stat(inPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0o640))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("directory has %d entries, want 2 (temporary files left behind?)", len(entries))
+	}
+}
+
+func TestConvertFileBOM(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithKeepBOM()}} {
+		converter, err := NewConverter("s2t.json", opts...)
+		if err != nil {
+			t.Fatalf("NewConverter() error = %v", err)
+		}
+		defer converter.Close()
+
+		path := filepath.Join(t.TempDir(), "bom.txt")
+		if err := os.WriteFile(path, []byte("\ufeff简体字\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := converter.ConvertFile(path, path); err != nil {
+			t.Fatalf("ConvertFile() error = %v", err)
+		}
+		checkFile(t, path, "\ufeff簡體字\n")
+	}
+}
+
+func TestConvertFileError(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.txt")
+	if err := os.WriteFile(inPath, []byte("简体\xff字"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(dir, "out.txt")
+	if err := converter.ConvertFile(inPath, outPath); err == nil {
+		t.Fatal("ConvertFile() error = nil, want non-nil")
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("output exists after failed conversion: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want 1 (temporary files left behind?)", len(entries))
+	}
+}
+
+func checkFile(t *testing.T, path, want string) {
+	t.Helper()
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("%s = %q, want %q", filepath.Base(path), got, want)
+	}
+}
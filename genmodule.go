@@ -0,0 +1,97 @@
+package opencc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"testing/fstest"
+)
+
+// genModule opens configurations generated from a converter's in a module
+// instance of its own, which sees the converter's files with the generated
+// ones over them. The generated files sit next to the converter's
+// configuration, so they refer to its dictionaries as it does.
+type genModule struct {
+	mod   *module
+	files fstest.MapFS // generated
+	dir   string       // of the converter's configuration
+	n     int          // files generated so far
+}
+
+// newGenModule instantiates a module for o that sees base, the files
+// mounted for configFile, with the generated files over them.
+func newGenModule(ctx context.Context, o *options, base fs.FS, configFile string) (*genModule, error) {
+	m := &genModule{
+		files: make(fstest.MapFS),
+		dir:   path.Dir(path.Clean(strings.TrimPrefix(configFile, "/"))),
+	}
+	o.dataFS = overlayFS{files: m.files, base: base}
+	mod, err := o.engine.newModule(ctx, o)
+	if err != nil {
+		return nil, fmt.Errorf("init module: %w", err)
+	}
+	m.mod = mod
+	return m, nil
+}
+
+func (m *genModule) close() error {
+	return m.mod.close()
+}
+
+// addDict adds a text dictionary of entries and returns its name relative
+// to the configuration.
+func (m *genModule) addDict(entries map[string]string) string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		buf.WriteString(key)
+		buf.WriteByte('\t')
+		buf.WriteString(entries[key])
+		buf.WriteByte('\n')
+	}
+	return m.add("txt", buf.Bytes())
+}
+
+// add adds a generated file with extension ext and returns its name
+// relative to the configuration.
+func (m *genModule) add(ext string, data []byte) string {
+	m.n++
+	name := fmt.Sprintf("opencc-gen-%d.%s", m.n, ext)
+	m.files[path.Join(m.dir, name)] = &fstest.MapFile{Data: data}
+	return name
+}
+
+// convert opens config, converts input with it and closes it again.
+func (m *genModule) convert(ctx context.Context, config map[string]any, input string) (string, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	configFile := path.Join(m.dir, m.add("json", data))
+
+	handle, err := m.mod.open(ctx, configFile)
+	if err != nil {
+		return "", err
+	}
+	var out string
+	err = m.mod.call(ctx, "opencc_convert", &out, handle, input)
+	var result int32
+	if closeErr := m.mod.call(ctx, "opencc_close", &result, handle); closeErr != nil {
+		err = errors.Join(err, fmt.Errorf("close converter: %w", closeErr))
+	}
+	if err != nil {
+		return "", fmt.Errorf("convert: %w", err)
+	}
+	return out, nil
+}
@@ -4,4 +4,7 @@ go 1.22.0
 
 toolchain go1.24.4
 
-require github.com/tetratelabs/wazero v1.9.0
+require (
+	github.com/tetratelabs/wazero v1.9.0
+	golang.org/x/text v0.21.0
+)
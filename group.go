@@ -0,0 +1,82 @@
+package opencc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ConverterGroup converts the same input under several configurations at
+// once, such as s2t.json, s2tw.json and s2hk.json, to compare the results.
+// Each member has its own module instance, so they run concurrently. Like
+// a Converter, it is safe for concurrent use.
+type ConverterGroup struct {
+	members map[string]*Converter
+}
+
+// NewConverterGroup opens a member for each of configs. The configurations
+// must be distinct, since ConvertAll keys its results by them. No member is
+// opened unless all of them exist, and if one fails to open, no group is
+// returned.
+func NewConverterGroup(configs ...string) (*ConverterGroup, error) {
+	for i, config := range configs {
+		if slices.Contains(configs[:i], config) {
+			return nil, fmt.Errorf("converter group: duplicate configuration %q", config)
+		}
+	}
+
+	converters, err := openConverters("converter group", configs)
+	if err != nil {
+		return nil, err
+	}
+	group := &ConverterGroup{members: make(map[string]*Converter, len(converters))}
+	for i, c := range converters {
+		group.members[configs[i]] = c
+	}
+	return group, nil
+}
+
+// ConvertAll converts input with every member of the group concurrently and
+// returns the results keyed by configuration name, as passed to
+// NewConverterGroup. If any conversion fails, the errors are returned
+// together, each prefixed with its configuration.
+func (g *ConverterGroup) ConvertAll(input string) (map[string]string, error) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		errs    []error
+		results = make(map[string]string, len(g.members))
+	)
+	for config, c := range g.members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result, err := c.Convert(input)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", config, err))
+				return
+			}
+			results[config] = result
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return results, nil
+}
+
+// Close closes every member of the group.
+func (g *ConverterGroup) Close() error {
+	var errs []error
+	for _, c := range g.members {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
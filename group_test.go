@@ -0,0 +1,66 @@
+package opencc
+
+import (
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestConverterGroup(t *testing.T) {
+	requireConfigs(t, "s2tw.json", "s2hk.json")
+	group, err := NewConverterGroup("s2t.json", "s2tw.json", "s2hk.json")
+	if err != nil {
+		t.Fatalf("NewConverterGroup() error = %v", err)
+	}
+
+	got, err := group.ConvertAll("着装")
+	if err != nil {
+		t.Fatalf("ConvertAll() error = %v", err)
+	}
+	want := make(map[string]string)
+	for _, config := range []string{"s2t.json", "s2tw.json", "s2hk.json"} {
+		c, err := NewConverter(config)
+		if err != nil {
+			t.Fatalf("NewConverter(%q) error = %v", config, err)
+		}
+		if want[config], err = c.Convert("着装"); err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		c.Close()
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("ConvertAll() = %v, want %v", got, want)
+	}
+
+	if err := group.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := group.ConvertAll("简体"); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("ConvertAll() after Close() error = %v, want %v", err, ErrInvalidConverter)
+	}
+}
+
+func TestConverterGroupInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs []string
+		wantErr error
+	}{
+		{"no configs", nil, nil},
+		{"missing config", []string{"s2t.json", "missing.json"}, ErrConfigNotFound},
+		{"duplicate config", []string{"s2t.json", "s2t.json"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := NewConverterGroup(tt.configs...)
+			if err == nil {
+				group.Close()
+				t.Fatal("NewConverterGroup() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewConverterGroup() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package opencc
+
+import (
+	"slices"
+	"strings"
+)
+
+// Converters backing the package-level helpers. They are created on first
+// use and reused across calls. The Taiwan, Hong Kong and Japanese pools are
+// declared next to their helpers, as build tags can leave them out.
+var (
+	s2tPool = NewConverterPool("s2t.json")
+	t2sPool = NewConverterPool("t2s.json")
+
+	defaultPools = slices.Concat([]*ConverterPool{s2tPool, t2sPool}, regionalPools, jpPools)
+)
+
+// ConvertS2T converts Simplified Chinese to Traditional Chinese
+func ConvertS2T(input string) (string, error) {
+	return convertPooled(s2tPool, input)
+}
+
+// ConvertT2S converts Traditional Chinese to Simplified Chinese
+func ConvertT2S(input string) (string, error) {
+	return convertPooled(t2sPool, input)
+}
+
+// Convert converts input with the bundled configuration config, such as
+// "s2hk" or "s2hk.json", so any direction is a single call. Like the other
+// package-level helpers it uses cached converters, created on first use,
+// and is safe for concurrent use. Configurations that aren't bundled return
+// ErrConfigNotFound.
+func Convert(config, input string) (string, error) {
+	name := configName(config)
+	p := defaultPool(name)
+	if p == nil {
+		return "", &ConversionError{Op: "open", Config: name, Err: ErrConfigNotFound}
+	}
+	return convertPooled(p, input)
+}
+
+// configName adds the .json extension to config if it is missing.
+func configName(config string) string {
+	if !strings.HasSuffix(config, ".json") {
+		return config + ".json"
+	}
+	return config
+}
+
+func convertPooled(p *ConverterPool, input string) (string, error) {
+	// Nothing to convert, so skip taking a converter from the pool
+	if input == "" {
+		return "", nil
+	}
+	return p.Convert(input)
+}
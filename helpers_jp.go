@@ -0,0 +1,22 @@
+//go:build !noopenccjp && !openccminimal
+
+package opencc
+
+var (
+	t2jpPool = NewConverterPool("t2jp.json")
+	jp2tPool = NewConverterPool("jp2t.json")
+
+	jpPools = []*ConverterPool{t2jpPool, jp2tPool}
+)
+
+// ConvertT2JP converts Traditional Chinese characters to the Japanese
+// Shinjitai kanji forms, e.g. 學 becomes 学
+func ConvertT2JP(input string) (string, error) {
+	return convertPooled(t2jpPool, input)
+}
+
+// ConvertJP2T converts Japanese Shinjitai kanji to Traditional Chinese
+// characters, e.g. 国 becomes 國
+func ConvertJP2T(input string) (string, error) {
+	return convertPooled(jp2tPool, input)
+}
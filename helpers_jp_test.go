@@ -0,0 +1,40 @@
+//go:build !noopenccjp && !openccminimal
+
+package opencc
+
+import "testing"
+
+func TestJapaneseHelpers(t *testing.T) {
+	tests := []struct {
+		name     string
+		convert  func(string) (string, error)
+		input    string
+		expected string
+	}{
+		{"T2JP", ConvertT2JP, "學國", "学国"},
+		{"T2JP empty", ConvertT2JP, "", ""},
+		{"JP2T", ConvertJP2T, "学国", "學國"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert%s() error = %v", tt.name, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Convert%s() = %v, want %v", tt.name, result, tt.expected)
+			}
+		})
+	}
+
+	converter, err := NewConverter("t2jp.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	if result, err := converter.Convert("學國"); err != nil || result != "学国" {
+		t.Errorf("Convert() = %q, %v, want %q, nil", result, err, "学国")
+	}
+}
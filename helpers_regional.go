@@ -0,0 +1,77 @@
+//go:build !openccminimal
+
+package opencc
+
+var (
+	s2twPool = NewConverterPool("s2tw.json")
+	tw2sPool = NewConverterPool("tw2s.json")
+	s2hkPool = NewConverterPool("s2hk.json")
+	hk2sPool = NewConverterPool("hk2s.json")
+	t2twPool = NewConverterPool("t2tw.json")
+	tw2tPool = NewConverterPool("tw2t.json")
+	t2hkPool = NewConverterPool("t2hk.json")
+	hk2tPool = NewConverterPool("hk2t.json")
+
+	s2twpPool = NewConverterPool("s2twp.json")
+	tw2spPool = NewConverterPool("tw2sp.json")
+
+	regionalPools = []*ConverterPool{
+		s2twPool, tw2sPool, s2hkPool, hk2sPool,
+		t2twPool, tw2tPool, t2hkPool, hk2tPool,
+		s2twpPool, tw2spPool,
+	}
+)
+
+// ConvertS2TW converts Simplified Chinese to Traditional Chinese (Taiwan standard)
+func ConvertS2TW(input string) (string, error) {
+	return convertPooled(s2twPool, input)
+}
+
+// ConvertTW2S converts Traditional Chinese (Taiwan standard) to Simplified Chinese
+func ConvertTW2S(input string) (string, error) {
+	return convertPooled(tw2sPool, input)
+}
+
+// ConvertS2HK converts Simplified Chinese to Traditional Chinese (Hong Kong variant)
+func ConvertS2HK(input string) (string, error) {
+	return convertPooled(s2hkPool, input)
+}
+
+// ConvertHK2S converts Traditional Chinese (Hong Kong variant) to Simplified Chinese
+func ConvertHK2S(input string) (string, error) {
+	return convertPooled(hk2sPool, input)
+}
+
+// ConvertT2TW converts Traditional Chinese to Traditional Chinese (Taiwan standard)
+func ConvertT2TW(input string) (string, error) {
+	return convertPooled(t2twPool, input)
+}
+
+// ConvertTW2T converts Traditional Chinese (Taiwan standard) to Traditional Chinese
+func ConvertTW2T(input string) (string, error) {
+	return convertPooled(tw2tPool, input)
+}
+
+// ConvertT2HK converts Traditional Chinese to Traditional Chinese (Hong Kong variant)
+func ConvertT2HK(input string) (string, error) {
+	return convertPooled(t2hkPool, input)
+}
+
+// ConvertHK2T converts Traditional Chinese (Hong Kong variant) to Traditional Chinese
+func ConvertHK2T(input string) (string, error) {
+	return convertPooled(hk2tPool, input)
+}
+
+// ConvertS2TWP converts Simplified Chinese to Traditional Chinese (Taiwan
+// standard), also replacing mainland vocabulary with the words used in
+// Taiwan, e.g. 鼠标 becomes 滑鼠
+func ConvertS2TWP(input string) (string, error) {
+	return convertPooled(s2twpPool, input)
+}
+
+// ConvertTW2SP converts Traditional Chinese (Taiwan standard) to Simplified
+// Chinese, also replacing Taiwan vocabulary with mainland words, e.g. 滑鼠
+// becomes 鼠标
+func ConvertTW2SP(input string) (string, error) {
+	return convertPooled(tw2spPool, input)
+}
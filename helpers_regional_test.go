@@ -0,0 +1,71 @@
+//go:build !openccminimal
+
+package opencc
+
+import "testing"
+
+func TestRegionalHelpers(t *testing.T) {
+	tests := []struct {
+		name     string
+		convert  func(string) (string, error)
+		input    string
+		expected string
+	}{
+		{"S2TW", ConvertS2TW, "里面着急", "裡面著急"},
+		{"S2TW empty", ConvertS2TW, "", ""},
+		{"TW2S", ConvertTW2S, "裡面著急", "里面着急"},
+		{"S2HK", ConvertS2HK, "卫生间里面", "衞生間裏面"},
+		{"HK2S", ConvertHK2S, "衞生間裏面", "卫生间里面"},
+		{"T2TW", ConvertT2TW, "裏面着急", "裡面著急"},
+		{"TW2T", ConvertTW2T, "裡面著急", "裏面着急"},
+		{"T2HK", ConvertT2HK, "衛生", "衞生"},
+		{"HK2T", ConvertHK2T, "衞生", "衛生"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert%s() error = %v", tt.name, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Convert%s() = %v, want %v", tt.name, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPhraseHelpers(t *testing.T) {
+	tests := []struct {
+		name      string
+		convert   func(string) (string, error)
+		plain     func(string) (string, error)
+		input     string
+		expected  string
+		plainWant string
+	}{
+		{"S2TWP", ConvertS2TWP, ConvertS2TW, "鼠标软件", "滑鼠軟體", "鼠標軟件"},
+		{"TW2SP", ConvertTW2SP, ConvertTW2S, "滑鼠軟體", "鼠标软件", "滑鼠软体"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert%s() error = %v", tt.name, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Convert%s() = %v, want %v", tt.name, result, tt.expected)
+			}
+
+			// The character-level config keeps the original vocabulary
+			plain, err := tt.plain(tt.input)
+			if err != nil {
+				t.Fatalf("plain conversion error = %v", err)
+			}
+			if plain != tt.plainWant {
+				t.Errorf("plain conversion = %v, want %v", plain, tt.plainWant)
+			}
+		})
+	}
+}
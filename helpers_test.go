@@ -0,0 +1,38 @@
+package opencc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		config   string
+		input    string
+		expected string
+		wantErr  error
+	}{
+		{"s2t", "简体字", "簡體字", nil},
+		{"s2t.json", "简体字", "簡體字", nil},
+		{"s2hk", "卫生间里面", "衞生間裏面", nil},
+		{"tw2s", "裡面著急", "里面着急", nil},
+		{"s2t", "", "", nil},
+		{"missing", "简体字", "", ErrConfigNotFound},
+		{"InstallScripts", "简体字", "", ErrConfigNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			if tt.wantErr == nil {
+				requireConfigs(t, tt.config)
+			}
+			result, err := Convert(tt.config, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Convert(%q) error = %v, want %v", tt.config, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("Convert(%q) = %v, want %v", tt.config, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// NewHTTPHandler returns an http.Handler that converts the body of POST
+// requests and writes the result as plain text. The configuration is taken
+// from the "config" query parameter, such as "s2t" or "s2t.json", and
+// defaults to config. Requests naming a configuration that isn't bundled
+// get 400 Bad Request, input that can't be converted 422 Unprocessable
+// Entity, input over the converters' size limit 413 Request Entity Too
+// Large, and any other failure 500 Internal Server Error.
+//
+// Conversions use the same cached converters as the package-level helpers
+// and are interrupted when the request context is done. The request body
+// isn't limited in size; wrap the handler with http.MaxBytesHandler to do
+// so.
+func NewHTTPHandler(config string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := config
+		if q := r.URL.Query().Get("config"); q != "" {
+			name = q
+		}
+		name = configName(name)
+		pool := defaultPool(name)
+		if pool == nil {
+			http.Error(w, "unknown config "+name, http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "read request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := convertPooledContext(r.Context(), pool, body)
+		if err != nil {
+			if r.Context().Err() != nil {
+				http.Error(w, r.Context().Err().Error(), http.StatusServiceUnavailable)
+				return
+			}
+			http.Error(w, err.Error(), convertStatus(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(result)
+	})
+}
+
+// convertStatus returns the status NewHTTPHandler responds with when
+// converting a request body failed with err. Only failures caused by the
+// input are the client's fault.
+func convertStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInputTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrInvalidInput), errors.Is(err, ErrConversionFailed):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// convertPooledContext converts body with a converter from p, giving up
+// waiting for one or interrupting the conversion when ctx is done.
+func convertPooledContext(ctx context.Context, p *ConverterPool, body []byte) ([]byte, error) {
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	c, err := p.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.Put(c)
+
+	var result []byte
+	if err := c.convert(ctx, &result, body); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// defaultPool returns the cached converters the package-level helpers use
+// for config, or nil if config isn't one of the bundled configurations.
+func defaultPool(config string) *ConverterPool {
+	configs, err := bundledConfigs()
+	if err != nil || !slices.Contains(configs, config) {
+		return nil
+	}
+	for _, p := range defaultPools {
+		if p.configFile == config {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	handler := NewHTTPHandler("s2t.json")
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+		want   string
+	}{
+		{"Default", http.MethodPost, "/", "简体字", http.StatusOK, "簡體字"},
+		{"Query", http.MethodPost, "/?config=t2s", "繁體字", http.StatusOK, "繁体字"},
+		{"QueryJSON", http.MethodPost, "/?config=t2s.json", "繁體字", http.StatusOK, "繁体字"},
+		{"Empty", http.MethodPost, "/", "", http.StatusOK, ""},
+		{"UnknownConfig", http.MethodPost, "/?config=nope", "简体字", http.StatusBadRequest, ""},
+		{"PathTraversal", http.MethodPost, "/?config=../opencc", "简体字", http.StatusBadRequest, ""},
+		{"InvalidInput", http.MethodPost, "/", "简体\xff字", http.StatusUnprocessableEntity, ""},
+		{"Method", http.MethodGet, "/", "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status == http.StatusOK && rec.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{&InputError{Offset: 1, Reason: "NUL byte"}, http.StatusUnprocessableEntity},
+		{&ConversionError{Op: "convert", Err: ErrConversionFailed}, http.StatusUnprocessableEntity},
+		{fmt.Errorf("%w: too big", ErrInputTooLarge), http.StatusRequestEntityTooLarge},
+		{ErrInvalidConverter, http.StatusInternalServerError},
+		{fmt.Errorf("convert: %w", ErrOutOfMemory), http.StatusInternalServerError},
+		{errors.New("init module: failed"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := convertStatus(tt.err); got != tt.want {
+			t.Errorf("convertStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPHandlerMaxBytes(t *testing.T) {
+	handler := http.MaxBytesHandler(NewHTTPHandler("s2t.json"), 4)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("简体字"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestConvertPooledContextWait(t *testing.T) {
+	pool := NewConverterPool("s2t.json", WithPoolSize(1))
+	defer pool.Close()
+
+	c, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer pool.Put(c)
+
+	// With the only converter in use, the request gives up when it's done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := convertPooledContext(ctx, pool, []byte("简体字")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("convertPooledContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,155 @@
+package opencc
+
+import (
+	"path"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// targetVariants maps the bundled configurations that convert between
+// Simplified and Traditional Chinese to the variant they produce.
+var targetVariants = map[Config]Variant{
+	ConfigS2T:   VariantTraditional,
+	ConfigS2TW:  VariantTraditional,
+	ConfigS2TWP: VariantTraditional,
+	ConfigS2HK:  VariantTraditional,
+	ConfigT2S:   VariantSimplified,
+	ConfigTW2S:  VariantSimplified,
+	ConfigTW2SP: VariantSimplified,
+	ConfigHK2S:  VariantSimplified,
+}
+
+var (
+	variantCharsMu  sync.Mutex
+	variantCharsMap map[rune]Variant
+)
+
+// variantChars maps the characters of the Basic Multilingual Plane's Han
+// blocks that are specific to one variant to that variant. It is built on
+// first use with two conversions, so scanning text is cheap afterwards. A
+// failure to build it isn't cached, so the next call tries again.
+func variantChars() (map[rune]Variant, error) {
+	variantCharsMu.Lock()
+	defer variantCharsMu.Unlock()
+
+	if variantCharsMap == nil {
+		chars, err := buildVariantChars()
+		if err != nil {
+			return nil, err
+		}
+		variantCharsMap = chars
+	}
+	return variantCharsMap, nil
+}
+
+// buildVariantChars builds the map variantChars returns.
+func buildVariantChars() (map[rune]Variant, error) {
+	var chars []rune
+	for _, block := range [][2]rune{
+		{0x3400, 0x4DBF}, // CJK Unified Ideographs Extension A
+		{0x4E00, 0x9FFF}, // CJK Unified Ideographs
+		{0xF900, 0xFAFF}, // CJK Compatibility Ideographs
+	} {
+		for r := block[0]; r <= block[1]; r++ {
+			if unicode.Is(unicode.Han, r) {
+				chars = append(chars, r)
+			}
+		}
+	}
+
+	variants, err := classifyRunes(chars)
+	if err != nil {
+		return nil, err
+	}
+	specific := make(map[rune]Variant)
+	for i, v := range variants {
+		if v != VariantUnknown {
+			specific[chars[i]] = v
+		}
+	}
+	return specific, nil
+}
+
+// ConvertIfNeeded converts input unless it already looks like it is written
+// in the variant the converter's configuration produces, in which case it
+// returns input unchanged and false. The check only applies to the bundled
+// configurations between Simplified and Traditional Chinese, such as s2t
+// and t2s. Others always convert, as do converters created with WithDataFS
+// or WithCustomDict, whose dictionaries may map characters the check
+// doesn't know about. Either way the byte order mark and WithNormalizeNFC
+// are handled as by Convert, so the result doesn't depend on whether a
+// conversion ran.
+//
+// The check is a heuristic: input is converted if it contains any character
+// specific to the other variant, as classified by DetectVariant. It ignores
+// regional vocabulary, so s2twp won't replace mainland terms in text that is
+// already Traditional, and characters outside the Basic Multilingual Plane.
+// Call Convert to force the conversion. The first call builds a table of
+// variant-specific characters, which takes a moment.
+func (c *Converter) ConvertIfNeeded(input string) (string, bool, error) {
+	c.mu.Lock()
+	closed, config, bundled := c.invalid(), c.config, c.bundledData()
+	var err error
+	if !closed {
+		err = c.checkInput(input)
+	}
+	c.mu.Unlock()
+
+	if closed {
+		return "", false, ErrInvalidConverter
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	target, ok := targetVariants[Config(path.Clean(strings.TrimPrefix(config, "/")))]
+	if ok && bundled {
+		text, hasBOM := c.prepare(input, true)
+		needed, err := hasVariantChars(text.(string), otherVariant(target))
+		if err != nil {
+			return "", false, err
+		}
+		if !needed {
+			result := text.(string)
+			if hasBOM && c.keepBOM {
+				result = bom + result
+			}
+			return result, false, nil
+		}
+	}
+
+	result, err := c.Convert(input)
+	if err != nil {
+		return "", false, err
+	}
+	return result, true, nil
+}
+
+// bundledData reports whether c converts with the embedded configurations
+// and dictionaries as they are. c.mu must be held.
+func (c *Converter) bundledData() bool {
+	return c.data == nil && len(c.options.customDicts) == 0
+}
+
+// otherVariant returns Traditional for Simplified and vice versa.
+func otherVariant(v Variant) Variant {
+	if v == VariantSimplified {
+		return VariantTraditional
+	}
+	return VariantSimplified
+}
+
+// hasVariantChars reports whether text contains a character specific to v.
+func hasVariantChars(text string, v Variant) (bool, error) {
+	chars, err := variantChars()
+	if err != nil {
+		return false, err
+	}
+	for _, r := range text {
+		if r >= 0x3400 && chars[r] == v {
+			return true, nil
+		}
+	}
+	return false, nil
+}
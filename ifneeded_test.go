@@ -0,0 +1,140 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestConvertIfNeeded(t *testing.T) {
+	tests := []struct {
+		config    string
+		input     string
+		want      string
+		converted bool
+	}{
+		{"s2t.json", "简体字", "簡體字", true},
+		{"s2t.json", "繁體字", "繁體字", false},
+		{"s2t.json", "繁體和简体", "繁體和簡體", true},
+		{"s2t.json", "Hello, 中文", "Hello, 中文", false},
+		{"s2t.json", "", "", false},
+		{"t2s.json", "繁體字", "繁体字", true},
+		{"t2s.json", "简体字", "简体字", false},
+		{"s2twp.json", "软件", "軟體", true},
+		// Other configurations always convert
+		{"t2tw.json", "繁體字", "繁體字", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.config+" "+tt.input, func(t *testing.T) {
+			requireConfigs(t, tt.config)
+			converter, err := NewConverter(tt.config)
+			if err != nil {
+				t.Fatalf("NewConverter() error = %v", err)
+			}
+			defer converter.Close()
+
+			got, converted, err := converter.ConvertIfNeeded(tt.input)
+			if err != nil {
+				t.Fatalf("ConvertIfNeeded() error = %v", err)
+			}
+			if got != tt.want || converted != tt.converted {
+				t.Errorf("ConvertIfNeeded(%q) = %q, %v, want %q, %v", tt.input, got, converted, tt.want, tt.converted)
+			}
+		})
+	}
+}
+
+func TestConvertIfNeededPreprocessing(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		input string
+		want  string
+	}{
+		{"BOM stripped", nil, "\ufeff繁體字", "繁體字"},
+		{"BOM kept", []Option{WithKeepBOM()}, "\ufeff繁體字", "\ufeff繁體字"},
+		{"NFC", []Option{WithNormalizeNFC()}, "\uf91d", "\u6b04"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter("s2t.json", tt.opts...)
+			if err != nil {
+				t.Fatalf("NewConverter() error = %v", err)
+			}
+			defer converter.Close()
+
+			// The same as Convert, although nothing was converted
+			got, converted, err := converter.ConvertIfNeeded(tt.input)
+			if err != nil || got != tt.want || converted {
+				t.Errorf("ConvertIfNeeded(%q) = %q, %v, %v, want %q, false, nil", tt.input, got, converted, err, tt.want)
+			}
+			if want, err := converter.Convert(tt.input); err != nil || got != want {
+				t.Errorf("Convert(%q) = %q, %v, want %q", tt.input, want, err, got)
+			}
+		})
+	}
+}
+
+func TestConvertIfNeededCustomData(t *testing.T) {
+	root, err := dataSubFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	converter, err := NewConverter("s2t.json", WithDataFS(root))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	// The dictionaries may not be the bundled ones, so it always converts
+	if got, converted, err := converter.ConvertIfNeeded("繁體字"); err != nil || got != "繁體字" || !converted {
+		t.Errorf("ConvertIfNeeded() = %q, %v, %v, want %q, true, nil", got, converted, err, "繁體字")
+	}
+}
+
+func TestConvertIfNeededErrors(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+
+	if _, _, err := converter.ConvertIfNeeded("繁體\xff"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("ConvertIfNeeded(invalid UTF-8) error = %v, want %v", err, ErrInvalidInput)
+	}
+
+	converter.Close()
+	if _, _, err := converter.ConvertIfNeeded("繁體字"); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("ConvertIfNeeded() after Close error = %v, want %v", err, ErrInvalidConverter)
+	}
+}
+
+func TestVariantCharsRetry(t *testing.T) {
+	// Leave too little memory to create the converters the table is built
+	// with, then lift the limit
+	Shutdown(context.Background())
+	SetMemoryLimitPages(16)
+	defer func() {
+		SetMemoryLimitPages(0)
+		Shutdown(context.Background())
+	}()
+
+	variantCharsMu.Lock()
+	variantCharsMap = nil
+	variantCharsMu.Unlock()
+
+	if _, err := variantChars(); err == nil {
+		t.Fatal("variantChars() error = nil, want error")
+	}
+
+	SetMemoryLimitPages(0)
+	Shutdown(context.Background())
+	chars, err := variantChars()
+	if err != nil {
+		t.Fatalf("variantChars() after a failure error = %v", err)
+	}
+	if chars['简'] != VariantSimplified {
+		t.Errorf("variantChars()['简'] = %v, want %v", chars['简'], VariantSimplified)
+	}
+}
@@ -0,0 +1,197 @@
+package opencc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// JSONOption configures ConvertJSON.
+type JSONOption func(*jsonOptions)
+
+type jsonOptions struct {
+	keys bool
+	skip func(key string) bool
+}
+
+// WithJSONKeys makes ConvertJSON convert object keys as well as values.
+// Keys of the same object that convert to the same key, such as the
+// Simplified and Traditional forms of a word, are an error rather than one
+// member silently replacing the other.
+func WithJSONKeys() JSONOption {
+	return func(o *jsonOptions) {
+		o.keys = true
+	}
+}
+
+// WithJSONSkipKey makes ConvertJSON leave the values of object members for
+// which skip returns true untouched, including everything nested in them,
+// so fields such as IDs aren't converted.
+func WithJSONSkipKey(skip func(key string) bool) JSONOption {
+	return func(o *jsonOptions) {
+		o.skip = skip
+	}
+}
+
+// ConvertJSON converts every string value in the JSON document data with c,
+// leaving the structure, numbers and booleans intact. The document is
+// walked token by token, so object members keep their order and numbers
+// are copied as written, however large. The result is compact, without
+// the whitespace of data.
+func ConvertJSON(c *Converter, data []byte, opts ...JSONOption) ([]byte, error) {
+	var o jsonOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	w := jsonWriter{c: c, o: &o, buf: &buf, enc: enc}
+
+	for !w.done {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode JSON: %w", err)
+		}
+		if err := w.write(tok); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("decode JSON: unexpected data after top-level value")
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonWriter writes the tokens of a JSON document back out, converting its
+// strings.
+type jsonWriter struct {
+	c   *Converter
+	o   *jsonOptions
+	buf *bytes.Buffer
+	enc *json.Encoder // writes strings to buf
+
+	stack []*jsonFrame // the arrays and objects being written
+	raw   int          // depth of containers in a skipped value
+	done  bool         // the top-level value is complete
+}
+
+// jsonFrame is an array or object being written.
+type jsonFrame struct {
+	object bool
+	key    bool              // the next token is an object key
+	skip   bool              // the current member's value is left as it is
+	n      int               // elements or members written
+	from   map[string]string // converted keys to the original ones
+}
+
+func (w *jsonWriter) write(tok json.Token) error {
+	var top *jsonFrame
+	if len(w.stack) > 0 {
+		top = w.stack[len(w.stack)-1]
+	}
+
+	if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+		w.buf.WriteByte(byte(d))
+		w.stack = w.stack[:len(w.stack)-1]
+		w.raw = max(w.raw-1, 0)
+		w.endValue()
+		return nil
+	}
+	if top != nil && top.object && top.key {
+		return w.writeKey(top, tok.(string))
+	}
+
+	if top != nil && !top.object && top.n > 0 {
+		w.buf.WriteByte(',')
+	}
+	skipped := w.raw > 0 || top != nil && top.object && top.skip
+	switch v := tok.(type) {
+	case json.Delim:
+		w.buf.WriteByte(byte(v))
+		w.stack = append(w.stack, &jsonFrame{object: v == '{', key: v == '{'})
+		if skipped {
+			w.raw++
+		}
+		return nil
+	case string:
+		if !skipped && v != "" {
+			converted, err := w.c.convertPart(v)
+			if err != nil {
+				return err
+			}
+			v = converted
+		}
+		w.writeString(v)
+	case json.Number:
+		w.buf.WriteString(string(v))
+	case bool:
+		w.buf.WriteString(strconv.FormatBool(v))
+	case nil:
+		w.buf.WriteString("null")
+	}
+	w.endValue()
+	return nil
+}
+
+// writeKey writes key, the name of the next member of top.
+func (w *jsonWriter) writeKey(top *jsonFrame, key string) error {
+	if top.n > 0 {
+		w.buf.WriteByte(',')
+	}
+	top.key = false
+	top.skip = w.o.skip != nil && w.o.skip(key)
+
+	if w.o.keys && w.raw == 0 && key != "" {
+		converted, err := w.c.convertPart(key)
+		if err != nil {
+			return err
+		}
+		if top.from == nil {
+			top.from = make(map[string]string)
+		}
+		// A key repeated in the input is kept repeated, like values are
+		if other, ok := top.from[converted]; ok && other != key {
+			keys := []string{other, key}
+			slices.Sort(keys)
+			return fmt.Errorf("convert JSON: keys %q and %q both convert to %q", keys[0], keys[1], converted)
+		}
+		top.from[converted] = key
+		key = converted
+	}
+	w.writeString(key)
+	w.buf.WriteByte(':')
+	return nil
+}
+
+// writeString writes s as a JSON string.
+func (w *jsonWriter) writeString(s string) {
+	// Encoding a string into a bytes.Buffer can't fail. Drop the newline
+	// Encode ends it with.
+	w.enc.Encode(s)
+	w.buf.Truncate(w.buf.Len() - 1)
+}
+
+// endValue records that a value of the innermost container, or the
+// top-level value, was written.
+func (w *jsonWriter) endValue() {
+	if len(w.stack) == 0 {
+		w.done = true
+		return
+	}
+	top := w.stack[len(w.stack)-1]
+	top.n++
+	if top.object {
+		top.key, top.skip = true, false
+	}
+}
@@ -0,0 +1,100 @@
+package opencc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestConvertJSON(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	input := `{"id":"简体","title":"简体字","count":12345678901234567890,"ok":true,"none":null,"tags":["汉字",{"简体":"转换"}],"empty":"","html":"<b>简体</b>"}`
+
+	tests := []struct {
+		name string
+		opts []JSONOption
+		want string
+	}{
+		{
+			name: "Values",
+			want: `{"id":"簡體","title":"簡體字","count":12345678901234567890,"ok":true,"none":null,"tags":["漢字",{"简体":"轉換"}],"empty":"","html":"<b>簡體</b>"}`,
+		},
+		{
+			name: "Keys",
+			opts: []JSONOption{WithJSONKeys()},
+			want: `{"id":"簡體","title":"簡體字","count":12345678901234567890,"ok":true,"none":null,"tags":["漢字",{"簡體":"轉換"}],"empty":"","html":"<b>簡體</b>"}`,
+		},
+		{
+			name: "SkipKey",
+			opts: []JSONOption{WithJSONSkipKey(func(key string) bool { return key == "id" || key == "tags" })},
+			want: `{"id":"简体","title":"簡體字","count":12345678901234567890,"ok":true,"none":null,"tags":["汉字",{"简体":"转换"}],"empty":"","html":"<b>簡體</b>"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertJSON(converter, []byte(input), tt.opts...)
+			if err != nil {
+				t.Fatalf("ConvertJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ConvertJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// Scalars at the top level
+	got, err := ConvertJSON(converter, []byte(`"简体"`))
+	if err != nil {
+		t.Fatalf("ConvertJSON() error = %v", err)
+	}
+	var s string
+	if err := json.Unmarshal(got, &s); err != nil || s != "簡體" {
+		t.Errorf("ConvertJSON() = %s, want %q", got, "簡體")
+	}
+
+	// Member order, number formatting and nested skipped values are kept;
+	// whitespace isn't
+	input = "{\n  \"z\": 1.50e+2,\n  \"a\": [-0, 1E3, \"简体\"],\n  \"id\": {\"简体\": [\"简体\", {}]},\n  \"m\": \"简体\"\n}\n"
+	got, err = ConvertJSON(converter, []byte(input), WithJSONKeys(), WithJSONSkipKey(func(key string) bool { return key == "id" }))
+	if want := `{"z":1.50e+2,"a":[-0,1E3,"簡體"],"id":{"简体":["简体",{}]},"m":"簡體"}`; err != nil || string(got) != want {
+		t.Errorf("ConvertJSON() = %s, %v, want %s", got, err, want)
+	}
+
+	for _, malformed := range []string{`{"a":`, `{} {}`, ``, `[1,]`, `{"a" 1}`} {
+		if _, err := ConvertJSON(converter, []byte(malformed)); err == nil {
+			t.Errorf("ConvertJSON(%q) error = nil for malformed JSON", malformed)
+		}
+	}
+}
+
+func TestConvertJSONKeyCollision(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	input := []byte(`{"data":{"简体":1,"簡體":2}}`)
+	_, err = ConvertJSON(converter, input, WithJSONKeys())
+	if want := `keys "简体" and "簡體" both convert to "簡體"`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("ConvertJSON() error = %v, want it to contain %q", err, want)
+	}
+
+	// A repeated key doesn't collide with itself
+	got, err := ConvertJSON(converter, []byte(`{"a":1,"a":2}`), WithJSONKeys())
+	if want := `{"a":1,"a":2}`; err != nil || string(got) != want {
+		t.Errorf("ConvertJSON() with a repeated key = %s, %v, want %s", got, err, want)
+	}
+
+	// Without WithJSONKeys the keys are kept as they are
+	got, err = ConvertJSON(converter, input)
+	if want := `{"data":{"简体":1,"簡體":2}}`; err != nil || string(got) != want {
+		t.Errorf("ConvertJSON() = %s, %v, want %s", got, err, want)
+	}
+}
@@ -0,0 +1,59 @@
+package opencc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// linesBatchSize is roughly how many bytes of lines ConvertLines joins into
+// a single conversion.
+const linesBatchSize = 16 << 10
+
+// ConvertLines converts independent lines using up to workers converters
+// from the pool at once and returns them in their original order. If
+// workers is not positive, GOMAXPROCS is used. Lines are joined into
+// batches separated by newlines, so each call into OpenCC converts many of
+// them; as a result a line can't contain a newline itself. On the first
+// failure no further batches are started, and the error reports the range
+// of lines of the batch that failed.
+func (p *ConverterPool) ConvertLines(lines []string, workers int) ([]string, error) {
+	var (
+		batches []string
+		starts  []int // index of the first line of each batch
+	)
+	for start := 0; start < len(lines); {
+		end, size := start, 0
+		for end < len(lines) && (end == start || size+len(lines[end]) < linesBatchSize) {
+			if strings.IndexByte(lines[end], '\n') >= 0 {
+				return nil, fmt.Errorf("convert line %d: %w", end, &InputError{
+					Offset: strings.IndexByte(lines[end], '\n'),
+					Reason: "newline in line",
+				})
+			}
+			size += len(lines[end]) + 1
+			end++
+		}
+		batches = append(batches, strings.Join(lines[start:end], "\n"))
+		starts = append(starts, start)
+		start = end
+	}
+	starts = append(starts, len(lines))
+
+	results, err := p.convertParallel(batches, workers, func(i int, err error) error {
+		return fmt.Errorf("convert lines %d-%d: %w", starts[i], starts[i+1]-1, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]string, 0, len(lines))
+	for i, result := range results {
+		split := strings.Split(result, "\n")
+		if want := starts[i+1] - starts[i]; len(split) != want {
+			return nil, fmt.Errorf("convert lines %d-%d: got %d lines back, want %d: %w",
+				starts[i], starts[i+1]-1, len(split), want, ErrConversionFailed)
+		}
+		converted = append(converted, split...)
+	}
+	return converted, nil
+}
@@ -0,0 +1,56 @@
+package opencc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConverterPoolConvertLines(t *testing.T) {
+	pool := NewConverterPool("s2t.json")
+	defer pool.Close()
+
+	// Enough lines for several batches
+	var lines, want []string
+	for i := range 3000 {
+		lines = append(lines, fmt.Sprintf("第%d行：简体字", i), "", "  ")
+		want = append(want, fmt.Sprintf("第%d行：簡體字", i), "", "  ")
+	}
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{"many lines", lines, want},
+		{"one line", []string{"简体字"}, []string{"簡體字"}},
+		{"empty lines", []string{"", ""}, []string{"", ""}},
+		{"no lines", nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pool.ConvertLines(tt.lines, 4)
+			if err != nil {
+				t.Fatalf("ConvertLines() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ConvertLines() returned %d lines, want %d", len(got), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestConverterPoolConvertLinesErrors(t *testing.T) {
+	pool := NewConverterPool("s2t.json")
+	defer pool.Close()
+
+	if _, err := pool.ConvertLines([]string{"ok", "a\nb"}, 0); !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("ConvertLines() with newline error = %v, want %v for line 1", err, ErrInvalidInput)
+	}
+	if _, err := pool.ConvertLines([]string{"ok", "a\x00b"}, 0); !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "lines 0-1") {
+		t.Errorf("ConvertLines() with NUL error = %v, want %v for lines 0-1", err, ErrInvalidInput)
+	}
+}
@@ -0,0 +1,26 @@
+package opencc
+
+import (
+	"io"
+	"log/slog"
+	"sync/atomic"
+)
+
+var (
+	discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	logger        atomic.Pointer[slog.Logger]
+)
+
+// SetLogger sets the logger used for warnings about cleanup failures and
+// exceptions thrown inside OpenCC. By default nothing is logged; passing nil
+// restores the default.
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+func getLogger() *slog.Logger {
+	if l := logger.Load(); l != nil {
+		return l
+	}
+	return discardLogger
+}
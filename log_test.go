@@ -0,0 +1,23 @@
+package opencc
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer SetLogger(nil)
+
+	if converter, err := NewConverterFromFS(missingDictFS(t), "s2t.json"); err == nil {
+		converter.Close()
+		t.Fatal("NewConverter() error = nil, want non-nil")
+	}
+
+	if !strings.Contains(buf.String(), "STPhrases.ocd2 not found") {
+		t.Errorf("log output = %q, want it to contain the exception message", buf.String())
+	}
+}
@@ -0,0 +1,162 @@
+package opencc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// LookupWord returns every candidate conversion of word listed in the
+// dictionaries of the converter's configuration, in dictionary order, where
+// Convert only picks the first. A word without a dictionary entry has its
+// conversion as the only candidate.
+//
+// The entry is looked up in the dictionary of the first conversion step,
+// read by the package itself, and its candidates are converted by the
+// remaining steps like segments of a conversion would be. Candidates that
+// become the same are listed once. Dictionaries of other types than "ocd2",
+// "text" and "group" can't be read and return an error wrapping
+// errors.ErrUnsupported.
+func (c *Converter) LookupWord(word string) ([]string, error) {
+	c.mu.Lock()
+	if c.invalid() {
+		c.mu.Unlock()
+		return nil, ErrInvalidConverter
+	}
+	err := c.checkInput(word)
+	o := *c.options
+	base, configFile := c.mount.fs, c.config
+	c.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	if word == "" || strings.ContainsRune(word, '\n') {
+		return nil, &InputError{Offset: max(strings.IndexByte(word, '\n'), 0), Reason: "word must be a single non-empty line"}
+	}
+
+	name := path.Clean(strings.TrimPrefix(configFile, "/"))
+	data, err := fs.ReadFile(base, name)
+	if err != nil {
+		return nil, &ConversionError{Op: "open", Config: configFile, Err: ErrConfigNotFound}
+	}
+	var config struct {
+		Steps []json.RawMessage `json:"conversion_chain"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", configFile, err)
+	}
+	if len(config.Steps) == 0 {
+		return nil, fmt.Errorf("%s: no conversion chain", configFile)
+	}
+	var step ConversionStep
+	if err := json.Unmarshal(config.Steps[0], &step); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", configFile, err)
+	}
+
+	candidates, err := lookupDict(base, path.Dir(name), step.Dict, word)
+	if err != nil {
+		return nil, fmt.Errorf("lookup: %w", err)
+	}
+	if candidates == nil {
+		converted, err := c.Convert(word)
+		if err != nil {
+			return nil, err
+		}
+		return []string{converted}, nil
+	}
+	if len(config.Steps) > 1 {
+		candidates, err = convertCandidates(context.Background(), &o, base, configFile, config.Steps[1:], candidates)
+		if err != nil {
+			return nil, fmt.Errorf("lookup: %w", err)
+		}
+	}
+
+	var unique []string
+	for _, candidate := range candidates {
+		if !slices.Contains(unique, candidate) {
+			unique = append(unique, candidate)
+		}
+	}
+	return unique, nil
+}
+
+// lookupDict returns the values of the entry for word in dict, whose files
+// are relative to dir in fsys, or nil if it has none. A group returns those
+// of its first dictionary with an entry, as OpenCC matches them.
+func lookupDict(fsys fs.FS, dir string, dict DictInfo, word string) ([]string, error) {
+	switch dict.Type {
+	case "group":
+		for _, d := range dict.Dicts {
+			values, err := lookupDict(fsys, dir, d, word)
+			if values != nil || err != nil {
+				return values, err
+			}
+		}
+		return nil, nil
+	case "ocd2":
+		data, err := fs.ReadFile(fsys, path.Join(dir, dict.File))
+		if err != nil {
+			return nil, err
+		}
+		entries, err := readOCD2(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dict.File, err)
+		}
+		return entries[word], nil
+	case "text":
+		data, err := fs.ReadFile(fsys, path.Join(dir, dict.File))
+		if err != nil {
+			return nil, err
+		}
+		// Each line is a key, a tab and its values separated by spaces
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			key, values, ok := strings.Cut(strings.TrimRight(string(line), "\r"), "\t")
+			if ok && key == word {
+				return strings.Fields(values), nil
+			}
+		}
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%s dictionary %s: %w", dict.Type, dict.File, errors.ErrUnsupported)
+	}
+}
+
+// convertCandidates converts each of candidates with steps, the remaining
+// conversion steps of configFile. The candidates are the entries of the
+// segmentation dictionary, so each is converted as one segment.
+func convertCandidates(ctx context.Context, o *options, base fs.FS, configFile string, steps []json.RawMessage, candidates []string) ([]string, error) {
+	m, err := newGenModule(ctx, o, base, configFile)
+	if err != nil {
+		return nil, err
+	}
+	defer m.close()
+
+	dict := make(map[string]string)
+	for _, candidate := range candidates {
+		dict[candidate] = candidate
+	}
+	out, err := m.convert(ctx, map[string]any{
+		"name": "lookup",
+		"segmentation": map[string]any{
+			"type": "mmseg",
+			"dict": map[string]any{"type": "text", "file": m.addDict(dict)},
+		},
+		"conversion_chain": steps,
+	}, strings.Join(candidates, "\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	converted := strings.Split(out, "\n")
+	if len(converted) != len(candidates) {
+		return nil, fmt.Errorf("unexpected conversion %q", out)
+	}
+	return converted, nil
+}
@@ -0,0 +1,36 @@
+//go:build !openccminimal
+
+package opencc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLookupWordChain(t *testing.T) {
+	// s2tw.json converts the candidates of its first step by TWVariants,
+	// which turns 裏 into 裡
+	converter, err := NewConverter("s2tw.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"里", []string{"裡", "里"}},
+		{"干", []string{"幹", "乾", "干"}},
+		{"着", []string{"著"}},
+	}
+	for _, tt := range tests {
+		got, err := converter.LookupWord(tt.word)
+		if err != nil {
+			t.Fatalf("LookupWord(%q) error = %v", tt.word, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("LookupWord(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
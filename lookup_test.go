@@ -0,0 +1,72 @@
+package opencc
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLookupWord(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"干", []string{"幹", "乾", "干"}},
+		{"里", []string{"裏", "里"}},
+		{"头发", []string{"頭髮"}},
+		// No entry: the conversion is the only candidate
+		{"汉字很简单", []string{"漢字很簡單"}},
+		{"abc", []string{"abc"}},
+	}
+	for _, tt := range tests {
+		got, err := converter.LookupWord(tt.word)
+		if err != nil {
+			t.Fatalf("LookupWord(%q) error = %v", tt.word, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("LookupWord(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestLookupWordCustomDict(t *testing.T) {
+	// Custom entries come first, with every value they list
+	converter, err := NewConverter("s2t.json", WithCustomDict(strings.NewReader("干\t干 榦\n")))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	got, err := converter.LookupWord("干")
+	if err != nil {
+		t.Fatalf("LookupWord() error = %v", err)
+	}
+	if want := []string{"干", "榦"}; !slices.Equal(got, want) {
+		t.Errorf("LookupWord() = %q, want %q", got, want)
+	}
+}
+
+func TestLookupWordInvalid(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+
+	for _, word := range []string{"", "简\n体", "简\xff"} {
+		if _, err := converter.LookupWord(word); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("LookupWord(%q) error = %v, want %v", word, err, ErrInvalidInput)
+		}
+	}
+
+	converter.Close()
+	if _, err := converter.LookupWord("干"); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("LookupWord() after Close() error = %v, want %v", err, ErrInvalidConverter)
+	}
+}
@@ -0,0 +1,227 @@
+package opencc
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+var (
+	// mdFence matches the opening line of a fenced code block.
+	mdFence = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	// mdLinkDef matches a link reference definition, such as
+	// "[label]: https://example.com".
+	mdLinkDef = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:`)
+	// mdAutolink matches an autolink, such as "<https://example.com>".
+	mdAutolink = regexp.MustCompile(`^<[A-Za-z][A-Za-z0-9+.-]{1,31}:[^<>\s]*>`)
+	// mdURL matches a bare URL.
+	mdURL = regexp.MustCompile(`^(?:https?|ftp)://[^\s<>]+`)
+)
+
+// ConvertMarkdown converts the prose of a Markdown document with c, leaving
+// code and URLs untouched: fenced and indented code blocks, code spans, link
+// destinations, reference labels and definitions, autolinks and bare URLs
+// are copied as is. Link text and image descriptions are converted.
+func ConvertMarkdown(c *Converter, src []byte) ([]byte, error) {
+	m := &mdConverter{c: c}
+	var fence string // closing fence of the current fenced code block
+	indented := false
+	blank := true // previous line was blank
+
+	for len(src) > 0 {
+		n := bytes.IndexByte(src, '\n') + 1
+		if n == 0 {
+			n = len(src)
+		}
+		line := string(src[:n])
+		src = src[n:]
+
+		content := strings.TrimRight(line, "\r\n")
+		isBlank := strings.TrimSpace(content) == ""
+
+		switch {
+		case fence != "":
+			if isFenceClose(content, fence) {
+				fence = ""
+			}
+			m.verbatim(line)
+		case mdFence.MatchString(content):
+			fence = mdFence.FindStringSubmatch(content)[1]
+			m.verbatim(line)
+		case !isBlank && (blank || indented) && isIndentedCode(content):
+			indented = true
+			m.verbatim(line)
+		case mdLinkDef.MatchString(content):
+			m.verbatim(line)
+		default:
+			if !isBlank {
+				indented = false
+			}
+			m.inline(line)
+		}
+		blank = isBlank
+	}
+
+	if err := m.flush(); err != nil {
+		return nil, err
+	}
+	return m.out.Bytes(), nil
+}
+
+// mdConverter accumulates prose so that it is converted in as few calls as
+// possible, and copies everything else verbatim.
+type mdConverter struct {
+	c     *Converter
+	out   bytes.Buffer
+	prose strings.Builder
+	err   error
+}
+
+func (m *mdConverter) text(s string) {
+	m.prose.WriteString(s)
+}
+
+func (m *mdConverter) verbatim(s string) {
+	m.flush()
+	m.out.WriteString(s)
+}
+
+// flush converts the pending prose.
+func (m *mdConverter) flush() error {
+	if m.err != nil || m.prose.Len() == 0 {
+		return m.err
+	}
+
+	s := m.prose.String()
+	m.prose.Reset()
+	if strings.TrimSpace(s) == "" {
+		m.out.WriteString(s)
+		return nil
+	}
+
+	result, err := m.c.convertPart(s)
+	if err != nil {
+		m.err = err
+		return err
+	}
+	m.out.WriteString(result)
+	return nil
+}
+
+// inline splits a line of prose into text to convert and code spans, link
+// destinations and URLs to copy.
+func (m *mdConverter) inline(line string) {
+	start := 0 // of pending text
+	for i := 0; i < len(line); {
+		end := 0 // of a verbatim span starting at i
+		switch line[i] {
+		case '\\':
+			i += 2
+			continue
+		case '`':
+			end = codeSpanEnd(line, i)
+		case '<':
+			end = i + len(mdAutolink.FindString(line[i:]))
+		case ']':
+			if i+1 < len(line) && line[i+1] == '(' {
+				m.text(line[start : i+1])
+				start, i = i+1, i+1
+				end = linkDestEnd(line, i)
+			} else if i+1 < len(line) && line[i+1] == '[' {
+				m.text(line[start : i+1])
+				start, i = i+1, i+1
+				if j := strings.IndexByte(line[i:], ']'); j >= 0 {
+					end = i + j + 1
+				}
+			}
+		case 'h', 'f':
+			end = i + len(mdURL.FindString(line[i:]))
+		}
+
+		if end > i {
+			m.text(line[start:i])
+			m.verbatim(line[i:end])
+			start, i = end, end
+			continue
+		}
+		if line[i] == '`' {
+			// Skip the whole run of unmatched backticks
+			for i < len(line) && line[i] == '`' {
+				i++
+			}
+			continue
+		}
+		i++
+	}
+	m.text(line[start:])
+}
+
+// codeSpanEnd returns the end of the code span starting with the backtick
+// run at line[i], or i if the run isn't closed by one of the same length.
+func codeSpanEnd(line string, i int) int {
+	n := 0
+	for i+n < len(line) && line[i+n] == '`' {
+		n++
+	}
+	for j := i + n; j < len(line); {
+		if line[j] != '`' {
+			j++
+			continue
+		}
+		k := j
+		for k < len(line) && line[k] == '`' {
+			k++
+		}
+		if k-j == n {
+			return k
+		}
+		j = k
+	}
+	return i
+}
+
+// linkDestEnd returns the end of the parenthesized link destination
+// starting at line[i], or i if it isn't closed.
+func linkDestEnd(line string, i int) int {
+	depth := 0
+	for j := i; j < len(line); j++ {
+		switch line[j] {
+		case '\\':
+			j++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return i
+}
+
+// isFenceClose reports whether line closes a code block opened by fence.
+func isFenceClose(line, fence string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return false
+	}
+	run := strings.TrimLeft(trimmed, fence[:1])
+	return len(trimmed)-len(run) >= len(fence) && strings.TrimSpace(run) == ""
+}
+
+// isIndentedCode reports whether line is indented by at least four columns.
+func isIndentedCode(line string) bool {
+	col := 0
+	for i := 0; i < len(line) && col < 4; i++ {
+		switch line[i] {
+		case ' ':
+			col++
+		case '\t':
+			col += 4 - col%4
+		default:
+			return false
+		}
+	}
+	return col >= 4
+}
@@ -0,0 +1,70 @@
+package opencc
+
+import "testing"
+
+func TestConvertMarkdown(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "Prose",
+			input: "# 简体标题\n\n这是简体字。\n",
+			want:  "# 簡體標題\n\n這是簡體字。\n",
+		},
+		{
+			name:  "Fence",
+			input: "简体\n```go\n// 简体注释\n```\n简体\n~~~~\n简体\n~~~\n简体\n~~~~\n",
+			want:  "簡體\n```go\n// 简体注释\n```\n簡體\n~~~~\n简体\n~~~\n简体\n~~~~\n",
+		},
+		{
+			name:  "UnclosedFence",
+			input: "```\n简体\n",
+			want:  "```\n简体\n",
+		},
+		{
+			name:  "IndentedCode",
+			input: "简体\n\n    简体代码\n\t简体代码\n\n简体\n    续行\n",
+			want:  "簡體\n\n    简体代码\n\t简体代码\n\n簡體\n    續行\n",
+		},
+		{
+			name:  "CodeSpan",
+			input: "使用 `简体` 和 ``含有 ` 的简体`` 以及 \\`简体\\` 和 ```未闭合",
+			want:  "使用 `简体` 和 ``含有 ` 的简体`` 以及 \\`簡體\\` 和 ```未閉合",
+		},
+		{
+			name:  "Links",
+			input: "[简体链接](https://example.com/简体 \"简体\") 和 ![简体图片](图片.png) 和 [简体][标签]\n\n[标签]: https://example.com/简体\n",
+			want:  "[簡體鏈接](https://example.com/简体 \"简体\") 和 ![簡體圖片](图片.png) 和 [簡體][标签]\n\n[标签]: https://example.com/简体\n",
+		},
+		{
+			name:  "URLs",
+			input: "访问 <https://example.com/简体> 或 https://example.com/简体 网站",
+			want:  "訪問 <https://example.com/简体> 或 https://example.com/简体 網站",
+		},
+		{
+			name:  "CRLF",
+			input: "简体\r\n```\r\n简体\r\n```\r\n",
+			want:  "簡體\r\n```\r\n简体\r\n```\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertMarkdown(converter, []byte(tt.input))
+			if err != nil {
+				t.Fatalf("ConvertMarkdown() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ConvertMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package opencc
+
+// MemoryStats describes the WASM linear memory held by a converter.
+type MemoryStats struct {
+	// Size is the size of linear memory in bytes. WASM memory never
+	// shrinks, so this is also the most the converter has ever used.
+	Size uint64
+
+	// Pages is Size in 64 KiB WASM pages.
+	Pages uint32
+}
+
+// MemoryStats returns the current size of c's linear memory. A converter
+// whose memory keeps growing over many conversions is a candidate for
+// pooling with fewer instances or periodic recycling.
+func (c *Converter) MemoryStats() (MemoryStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.invalid() {
+		return MemoryStats{}, ErrInvalidConverter
+	}
+
+	// Memory.Size overflows at 4 GiB, so count pages instead
+	pages, _ := c.mod.mod.Memory().Grow(0)
+	return MemoryStats{
+		Size:  uint64(pages) * wasmPageSize,
+		Pages: pages,
+	}, nil
+}
+
+// wasmPageSize is the size of a WASM memory page
+const wasmPageSize = 1 << 16
@@ -0,0 +1,38 @@
+package opencc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConverterMemoryStats(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+
+	before, err := converter.MemoryStats()
+	if err != nil {
+		t.Fatalf("MemoryStats() error = %v", err)
+	}
+	if before.Pages == 0 || before.Size != uint64(before.Pages)*wasmPageSize {
+		t.Errorf("MemoryStats() = %+v, want Size = Pages * 64 KiB", before)
+	}
+
+	if _, err := converter.Convert(strings.Repeat("简体字", 1<<16)); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	after, err := converter.MemoryStats()
+	if err != nil {
+		t.Fatalf("MemoryStats() error = %v", err)
+	}
+	if after.Size <= before.Size {
+		t.Errorf("MemoryStats().Size = %d after a large conversion, want more than %d", after.Size, before.Size)
+	}
+
+	converter.Close()
+	if _, err := converter.MemoryStats(); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("MemoryStats() after Close error = %v, want ErrInvalidConverter", err)
+	}
+}
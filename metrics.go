@@ -0,0 +1,65 @@
+package opencc
+
+import "sync/atomic"
+
+// Metrics receives counters for the conversions converters run, so they
+// can be exported to a monitoring system such as Prometheus. Each Convert,
+// ConvertBytes or ConvertTo call counts as one conversion, even when
+// options such as WithPreserveWhitespace split it into several calls into
+// OpenCC, as do each input of ConvertBatch and each chunk of ConvertStream,
+// including those run by pools and the package-level helpers.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// IncConversions counts an attempted conversion
+	IncConversions()
+	// AddInputBytes adds the size of a conversion's input
+	AddInputBytes(n int)
+	// AddOutputBytes adds the size of a successful conversion's output
+	AddOutputBytes(n int)
+	// IncErrors counts a failed conversion
+	IncErrors()
+}
+
+var metrics atomic.Pointer[Metrics]
+
+// SetMetrics sets where conversion counters are reported. By default
+// nothing is recorded; passing nil restores the default.
+func SetMetrics(m Metrics) {
+	if m == nil {
+		metrics.Store(nil)
+		return
+	}
+	metrics.Store(&m)
+}
+
+// recordMetrics reports a conversion of input into dest that returned err.
+func recordMetrics(dest, input any, err error) {
+	p := metrics.Load()
+	if p == nil {
+		return
+	}
+	m := *p
+
+	m.IncConversions()
+	m.AddInputBytes(inputLen(input))
+	if err != nil {
+		m.IncErrors()
+		return
+	}
+
+	m.AddOutputBytes(outputLen(dest))
+}
+
+// outputLen returns the length in bytes of a conversion result stored in
+// dest.
+func outputLen(dest any) int {
+	switch d := dest.(type) {
+	case *string:
+		return len(*d)
+	case *[]byte:
+		return len(*d)
+	case *writerDest:
+		return d.n
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+package opencc
+
+import (
+	"bytes"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type countingMetrics struct {
+	conversions, inputBytes, outputBytes, errors atomic.Int64
+}
+
+func (m *countingMetrics) IncConversions()      { m.conversions.Add(1) }
+func (m *countingMetrics) AddInputBytes(n int)  { m.inputBytes.Add(int64(n)) }
+func (m *countingMetrics) AddOutputBytes(n int) { m.outputBytes.Add(int64(n)) }
+func (m *countingMetrics) IncErrors()           { m.errors.Add(1) }
+
+func TestSetMetrics(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	m := new(countingMetrics)
+	SetMetrics(m)
+	defer SetMetrics(nil)
+
+	// 9 bytes in, 9 bytes out each
+	if _, err := converter.Convert("简体字"); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if _, err := converter.ConvertBatch([]string{"简体字", "简体字"}); err != nil {
+		t.Fatalf("ConvertBatch() error = %v", err)
+	}
+	var out bytes.Buffer
+	if err := converter.ConvertStream(strings.NewReader("简体字"), &out); err != nil {
+		t.Fatalf("ConvertStream() error = %v", err)
+	}
+	if _, err := converter.Convert("a\x00b"); err == nil {
+		t.Fatal("Convert() with NUL byte error = nil, want error")
+	}
+
+	tests := []struct {
+		name string
+		got  int64
+		want int64
+	}{
+		{"conversions", m.conversions.Load(), 5},
+		{"input bytes", m.inputBytes.Load(), 4*9 + 3},
+		{"output bytes", m.outputBytes.Load(), 4 * 9},
+		{"errors", m.errors.Load(), 1},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+
+	SetMetrics(nil)
+	if _, err := converter.Convert("简体字"); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if got := m.conversions.Load(); got != 5 {
+		t.Errorf("conversions after SetMetrics(nil) = %d, want 5", got)
+	}
+}
@@ -0,0 +1,80 @@
+package opencc
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// configFS narrows root down to configFile and the dictionaries it
+// references, so a converter's module only sees the files it needs. If the
+// configuration can't be parsed, root is returned unchanged.
+func configFS(root fs.FS, configFile string) fs.FS {
+	name := path.Clean(strings.TrimPrefix(configFile, "/"))
+	data, err := fs.ReadFile(root, name)
+	if err != nil {
+		return root
+	}
+	var config any
+	if err := json.Unmarshal(data, &config); err != nil {
+		return root
+	}
+
+	allowed := map[string]bool{".": true}
+	allow := func(name string) {
+		for ; name != "." && !allowed[name]; name = path.Dir(name) {
+			allowed[name] = true
+		}
+	}
+	allow(name)
+	for _, file := range dictFiles(config) {
+		allow(path.Join(path.Dir(name), file))
+	}
+	return filterFS{base: root, allowed: allowed}
+}
+
+// dictFiles returns the values of all "file" members in a parsed
+// configuration.
+func dictFiles(v any) []string {
+	var files []string
+	switch v := v.(type) {
+	case map[string]any:
+		for key, elem := range v {
+			if file, ok := elem.(string); ok && key == "file" {
+				files = append(files, file)
+				continue
+			}
+			files = append(files, dictFiles(elem)...)
+		}
+	case []any:
+		for _, elem := range v {
+			files = append(files, dictFiles(elem)...)
+		}
+	}
+	return files
+}
+
+// filterFS exposes only the allowed files and directories of base.
+type filterFS struct {
+	base    fs.FS
+	allowed map[string]bool
+}
+
+func (f filterFS) Open(name string) (fs.File, error) {
+	if !f.allowed[name] {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return f.base.Open(name)
+}
+
+// swapFS forwards to fs, which Converter.Reset replaces to show the module
+// the files of another configuration. fs is guarded by the converter's
+// mutex, as the module only reads files while it is held.
+type swapFS struct {
+	fs fs.FS
+}
+
+func (s *swapFS) Open(name string) (fs.File, error) {
+	return s.fs.Open(name)
+}
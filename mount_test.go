@@ -0,0 +1,67 @@
+package opencc
+
+import (
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestConfigFS(t *testing.T) {
+	root := fstest.MapFS{
+		"s2t.json":          {Data: []byte(`{"segmentation":{"dict":{"type":"ocd2","file":"A.ocd2"}},"conversion_chain":[{"dict":{"type":"group","dicts":[{"file":"A.ocd2"},{"file":"B.ocd2"}]}}]}`)},
+		"A.ocd2":            {},
+		"B.ocd2":            {},
+		"C.ocd2":            {},
+		"sub/t2s.json":      {Data: []byte(`{"conversion_chain":[{"dict":{"file":"D.ocd2"}}]}`)},
+		"sub/D.ocd2":        {},
+		"broken.json":       {Data: []byte(`{`)},
+		"sub/unrelated.txt": {},
+	}
+
+	tests := []struct {
+		config string
+		want   []string
+	}{
+		{"s2t.json", []string{"A.ocd2", "B.ocd2", "s2t.json"}},
+		{"/sub/t2s.json", []string{"sub/D.ocd2", "sub/t2s.json"}},
+		{"broken.json", []string{"A.ocd2", "B.ocd2", "C.ocd2", "broken.json", "s2t.json", "sub/D.ocd2", "sub/t2s.json", "sub/unrelated.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			fsys := configFS(root, tt.config)
+
+			var got []string
+			for name := range root {
+				if _, err := fs.Stat(fsys, name); err == nil {
+					got = append(got, name)
+				}
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("configFS(%q) exposes %v, want %v", tt.config, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFSSharesData(t *testing.T) {
+	root, err := dataSubFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := dataSubFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every converter mounts a view of the same decompressed dictionaries
+	files := root.(fstest.MapFS)
+	if &files["STPhrases.ocd2"].Data[0] != &again.(fstest.MapFS)["STPhrases.ocd2"].Data[0] {
+		t.Error("dictionaries are decompressed again for each converter")
+	}
+	if f, ok := configFS(root, "s2t.json").(filterFS); !ok || f.base.(fstest.MapFS) == nil {
+		t.Errorf("configFS() = %T, want a filterFS over the shared data", configFS(root, "s2t.json"))
+	}
+}
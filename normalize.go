@@ -0,0 +1,19 @@
+package opencc
+
+import "golang.org/x/text/unicode/norm"
+
+// normalizeNFC returns input in Unicode Normalization Form C. Input that
+// already is, as most text is, is returned as it is without copying.
+func normalizeNFC(input any) any {
+	switch v := input.(type) {
+	case string:
+		if !norm.NFC.IsNormalString(v) {
+			return norm.NFC.String(v)
+		}
+	case []byte:
+		if !norm.NFC.IsNormal(v) {
+			return norm.NFC.Bytes(v)
+		}
+	}
+	return input
+}
@@ -0,0 +1,35 @@
+package opencc
+
+import "testing"
+
+func TestWithNormalizeNFC(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		input string
+		want  string
+	}{
+		{"compatibility ideograph", nil, "\uF91D", "\uF91D"},
+		{"compatibility ideograph normalized", []Option{WithNormalizeNFC()}, "\uF91D", "栏"},
+		{"decomposed", nil, "e\u0301欄", "e\u0301栏"},
+		{"decomposed normalized", []Option{WithNormalizeNFC()}, "e\u0301欄", "\u00e9栏"},
+		{"already NFC", []Option{WithNormalizeNFC()}, "\u00e9欄", "\u00e9栏"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter("t2s.json", tt.opts...)
+			if err != nil {
+				t.Fatalf("NewConverter() error = %v", err)
+			}
+			defer converter.Close()
+
+			if got, err := converter.Convert(tt.input); err != nil || got != tt.want {
+				t.Errorf("Convert(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+			}
+			if got, err := converter.ConvertBytes([]byte(tt.input)); err != nil || string(got) != tt.want {
+				t.Errorf("ConvertBytes(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package opencc
+
+import (
+	"bytes"
+	endian "encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+// ocd2Header starts every dictionary in OpenCC's ocd2 format, followed by
+// a marisa-trie holding the keys and then the values of each key in the
+// order of its ID in the trie.
+const ocd2Header = "OPENCC_MARISA_0.2.5"
+
+// marisaHeader starts a serialized marisa-trie.
+const marisaHeader = "We love Marisa.\x00"
+
+// Tail modes of a marisa-trie, from its configuration flags.
+const (
+	marisaTextTail   = 0x01000
+	marisaBinaryTail = 0x02000
+	marisaTailMask   = 0x0f000
+)
+
+var errCorruptDict = errors.New("corrupt ocd2 dictionary")
+
+// readOCD2 reads every entry of a dictionary in ocd2 format.
+func readOCD2(data []byte) (map[string][]string, error) {
+	if !bytes.HasPrefix(data, []byte(ocd2Header)) {
+		return nil, fmt.Errorf("%w: no ocd2 header", errCorruptDict)
+	}
+	r := &dictReader{data: data[len(ocd2Header):]}
+	if string(r.bytes(len(marisaHeader))) != marisaHeader {
+		return nil, fmt.Errorf("%w: no marisa-trie header", errCorruptDict)
+	}
+	trie := r.trie(0)
+
+	// Values: the number of entries, all values terminated by NUL, then for
+	// each entry the number of its values and their lengths with the NUL
+	n := int(r.u32())
+	buf := r.bytes(int(r.u32()))
+	if r.err != nil {
+		return nil, r.err
+	}
+	if n != len(trie.terminal.ones) {
+		return nil, fmt.Errorf("%w: %d values for %d keys", errCorruptDict, n, len(trie.terminal.ones))
+	}
+
+	entries := make(map[string][]string, n)
+	for id := 0; id < n; id++ {
+		values := make([]string, r.u16())
+		for i := range values {
+			size := int(r.u16())
+			if size == 0 || size > len(buf) {
+				return nil, fmt.Errorf("%w: value out of range", errCorruptDict)
+			}
+			values[i] = string(buf[:size-1])
+			buf = buf[size:]
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		key, err := trie.key(id)
+		if err != nil {
+			return nil, err
+		}
+		entries[key] = values
+	}
+	return entries, nil
+}
+
+// dictReader reads the little-endian structures of an ocd2 dictionary,
+// recording the first error.
+type dictReader struct {
+	data []byte
+	err  error
+}
+
+func (r *dictReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = fmt.Errorf("%w: truncated", errCorruptDict)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *dictReader) u16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return endian.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *dictReader) u32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return endian.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *dictReader) u64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return endian.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// vector reads a marisa vector: its size in bytes, its contents and padding
+// up to a multiple of 8 bytes.
+func (r *dictReader) vector() []byte {
+	size := r.u64()
+	if size > uint64(len(r.data)) {
+		r.err = fmt.Errorf("%w: truncated", errCorruptDict)
+		return nil
+	}
+	b := r.bytes(int(size))
+	r.bytes(int(-size % 8))
+	return b
+}
+
+// units reads a marisa vector of 64-bit units.
+func (r *dictReader) units() []uint64 {
+	b := r.vector()
+	units := make([]uint64, len(b)/8)
+	for i := range units {
+		units[i] = endian.LittleEndian.Uint64(b[i*8:])
+	}
+	return units
+}
+
+// bitVector reads a marisa bit vector. Its rank and select indexes are
+// skipped, and the positions of its set bits listed instead.
+func (r *dictReader) bitVector() bitVector {
+	v := bitVector{units: r.units()}
+	size := int(r.u32())
+	r.u32() // number of set bits
+	r.vector()
+	r.vector()
+	r.vector()
+	if size > len(v.units)*64 {
+		r.err = fmt.Errorf("%w: bit vector out of range", errCorruptDict)
+		return bitVector{}
+	}
+	for i, unit := range v.units {
+		for ; unit != 0; unit &= unit - 1 {
+			if pos := i*64 + bits.TrailingZeros64(unit); pos < size {
+				v.ones = append(v.ones, pos)
+			}
+		}
+	}
+	return v
+}
+
+// flatVector reads a marisa vector of integers packed into value bits each.
+func (r *dictReader) flatVector() flatVector {
+	v := flatVector{units: r.units()}
+	v.width = uint(r.u32())
+	v.mask = uint64(r.u32())
+	size := r.u64()
+	if v.width > 32 || size*uint64(v.width) > uint64(len(v.units))*64 {
+		r.err = fmt.Errorf("%w: flat vector out of range", errCorruptDict)
+		return flatVector{}
+	}
+	return v
+}
+
+// trie reads a marisa LOUDS trie, followed by the next trie its links lead
+// to unless it stores them in a tail.
+func (r *dictReader) trie(depth int) *marisaTrie {
+	t := &marisaTrie{
+		louds:    r.bitVector(),
+		terminal: r.bitVector(),
+		link:     r.bitVector(),
+		bases:    r.vector(),
+		extras:   r.flatVector(),
+		tail:     r.vector(),
+		tailEnds: r.bitVector(),
+	}
+	if len(t.link.ones) > 0 && len(t.tail) == 0 && r.err == nil {
+		if depth > 8 {
+			r.err = fmt.Errorf("%w: too many tries", errCorruptDict)
+			return t
+		}
+		t.next = r.trie(depth + 1)
+	}
+	r.vector() // cache
+	t.numL1 = int(r.u32())
+	t.binaryTail = r.u32()&marisaTailMask == marisaBinaryTail
+	return t
+}
+
+// bitVector is a vector of bits with the positions of its set bits.
+type bitVector struct {
+	units []uint64
+	ones  []int
+}
+
+func (v bitVector) get(i int) bool {
+	return i/64 < len(v.units) && v.units[i/64]>>(i%64)&1 != 0
+}
+
+// rank1 returns the number of set bits before position i.
+func (v bitVector) rank1(i int) int {
+	n, _ := slices.BinarySearch(v.ones, i)
+	return n
+}
+
+// flatVector is a vector of integers packed into width bits each.
+type flatVector struct {
+	units []uint64
+	width uint
+	mask  uint64
+}
+
+func (v flatVector) get(i int) uint64 {
+	if v.width == 0 {
+		return 0
+	}
+	pos := uint(i) * v.width
+	unit, offset := pos/64, pos%64
+	if unit >= uint(len(v.units)) {
+		return 0
+	}
+	value := v.units[unit] >> offset
+	if offset+v.width > 64 && unit+1 < uint(len(v.units)) {
+		value |= v.units[unit+1] << (64 - offset)
+	}
+	return value & v.mask
+}
+
+// marisaTrie is one trie of a marisa-trie in LOUDS representation. Each
+// node's label is a byte, or a link to a string stored in the next trie or
+// the tail.
+type marisaTrie struct {
+	louds, terminal, link bitVector
+	bases                 []byte
+	extras                flatVector
+	tail                  []byte
+	tailEnds              bitVector
+	binaryTail            bool
+	next                  *marisaTrie
+	numL1                 int // nodes on the first level, the root's children
+}
+
+// key returns the key with ID id, walking from its terminal node up to the
+// root.
+func (t *marisaTrie) key(id int) (string, error) {
+	if id >= len(t.terminal.ones) {
+		return "", fmt.Errorf("%w: key out of range", errCorruptDict)
+	}
+	var key []byte
+	for node := t.terminal.ones[id]; node != 0; {
+		start := len(key)
+		var err error
+		if key, err = t.label(key, node); err != nil {
+			return "", err
+		}
+		// Labels are collected from the end of the key backwards
+		slices.Reverse(key[start:])
+		if node <= t.numL1 {
+			break
+		}
+		if node, err = t.parent(node); err != nil {
+			return "", err
+		}
+	}
+	slices.Reverse(key)
+	return string(key), nil
+}
+
+// restore appends the string a next trie's node and its ancestors store.
+// The next tries hold strings reversed, so walking up gives them in order.
+func (t *marisaTrie) restore(key []byte, node int) ([]byte, error) {
+	for {
+		var err error
+		if key, err = t.label(key, node); err != nil {
+			return nil, err
+		}
+		if node <= t.numL1 {
+			return key, nil
+		}
+		if node, err = t.parent(node); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// label appends the label of node: its byte, or the string it links to.
+func (t *marisaTrie) label(key []byte, node int) ([]byte, error) {
+	if node <= 0 || node >= len(t.bases) {
+		return nil, fmt.Errorf("%w: node out of range", errCorruptDict)
+	}
+	if !t.link.get(node) {
+		return append(key, t.bases[node]), nil
+	}
+
+	link := int(t.bases[node]) | int(t.extras.get(t.link.rank1(node)))<<8
+	if t.next != nil {
+		return t.next.restore(key, link)
+	}
+	for i := link; ; i++ {
+		if i >= len(t.tail) {
+			return nil, fmt.Errorf("%w: tail out of range", errCorruptDict)
+		}
+		if !t.binaryTail && t.tail[i] == 0 {
+			return key, nil
+		}
+		key = append(key, t.tail[i])
+		if t.binaryTail && t.tailEnds.get(i) {
+			return key, nil
+		}
+	}
+}
+
+// parent returns the parent of node.
+func (t *marisaTrie) parent(node int) (int, error) {
+	if node >= len(t.louds.ones) {
+		return 0, fmt.Errorf("%w: node out of range", errCorruptDict)
+	}
+	parent := t.louds.ones[node] - node - 1
+	if parent < 0 || parent >= node {
+		return 0, fmt.Errorf("%w: node out of range", errCorruptDict)
+	}
+	return parent, nil
+}
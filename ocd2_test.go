@@ -0,0 +1,66 @@
+package opencc
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+)
+
+func TestReadOCD2(t *testing.T) {
+	root, err := dataSubFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file  string
+		count int
+		key   string
+		want  []string
+	}{
+		{"STCharacters.ocd2", 3980, "干", []string{"幹", "乾", "干"}},
+		{"STPhrases.ocd2", 49096, "头发", []string{"頭髮"}},
+	}
+	for _, tt := range tests {
+		data, err := fs.ReadFile(root, tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, err := readOCD2(data)
+		if err != nil {
+			t.Fatalf("readOCD2(%s) error = %v", tt.file, err)
+		}
+		if len(entries) != tt.count {
+			t.Errorf("readOCD2(%s) has %d entries, want %d", tt.file, len(entries), tt.count)
+		}
+		if got := entries[tt.key]; !slices.Equal(got, tt.want) {
+			t.Errorf("readOCD2(%s)[%q] = %q, want %q", tt.file, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestReadOCD2Corrupt(t *testing.T) {
+	root, err := dataSubFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := fs.ReadFile(root, "STCharacters.ocd2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt := slices.Clone(data)
+	corrupt[len(ocd2Header)+len(marisaHeader)] ^= 0xff
+	for _, data := range [][]byte{
+		nil,
+		[]byte("not a dictionary"),
+		data[:len(data)/2],
+		data[:len(data)-1],
+		corrupt,
+	} {
+		if _, err := readOCD2(data); !errors.Is(err, errCorruptDict) {
+			t.Errorf("readOCD2() of %d bytes error = %v, want %v", len(data), err, errCorruptDict)
+		}
+	}
+}
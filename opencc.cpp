@@ -2,7 +2,9 @@
 #include <cstdlib>
 #include <cstring>
 #include <iostream>
+#include <string>
 
+#include "SimpleConverter.hpp"
 #include "opencc.h"
 
 __attribute__((export_name("malloc"))) void *exported_malloc(size_t size) {
@@ -35,6 +37,26 @@ opencc_wrapper_convert(opencc_t opencc, const char *input) {
   return opencc_convert_utf8(opencc, input, (size_t)-1);
 }
 
+// Like opencc_convert, but also stores the length of the result at length,
+// so the caller can read it without scanning for the terminator. The
+// result is allocated like opencc_convert_utf8 does, so opencc_convert_free
+// frees it.
+__attribute__((export_name("opencc_convert_len"))) char *
+opencc_wrapper_convert_len(opencc_t opencc, const char *input,
+                           size_t *length) {
+  if (!opencc || !input || !length) {
+    return nullptr;
+  }
+
+  const std::string converted =
+      reinterpret_cast<opencc::SimpleConverter *>(opencc)->Convert(input);
+  char *result = new char[converted.size() + 1];
+  converted.copy(result, converted.size());
+  result[converted.size()] = '\0';
+  *length = converted.size();
+  return result;
+}
+
 __attribute__((export_name("opencc_convert_free"))) void
 opencc_wrapper_convert_free(char *str) {
   opencc_convert_utf8_free(str);
@@ -1,17 +1,23 @@
 package opencc
 
 import (
+	"bytes"
 	"context"
-	"embed"
 	_ "embed"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
-	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
-	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 )
 
 //go:generate ./build.sh
@@ -19,16 +25,32 @@ import (
 //go:embed opencc.wasm
 var binary []byte // WASM blob
 
-//go:embed data/*
-var dataFS embed.FS
-
-var ErrInvalidConverter = fmt.Errorf("invalid converter")
-var ErrConversionFailed = fmt.Errorf("conversion failed")
-
-// Converter represents an OpenCC converter instance
+// Converter represents an OpenCC converter instance. It is safe for
+// concurrent use, but calls are serialized because they share one WASM
+// module instance; use a ConverterPool to convert in parallel.
 type Converter struct {
+	mu     sync.Mutex // guards mod, handle and config
 	mod    *module
 	handle uint32
+	config string
+	opts   []Option
+
+	// options holds the prepared options for reinstantiating the module
+	options *options
+	// data is the data filesystem before mounting a configuration, and
+	// mount is what the module sees of it
+	data  fs.FS
+	mount *swapFS
+
+	conversions  int // since the module was instantiated
+	recycleAfter int
+
+	maxInputSize   int
+	tracer         Tracer
+	skipValidation bool
+	keepBOM        bool
+	normalizeNFC   bool
+	preserve       func(rune) bool // runes kept as they are, if any
 }
 
 // NewConverter creates a new OpenCC converter with the specified configuration.
@@ -39,259 +61,707 @@ type Converter struct {
 //   - "s2hk.json" - Simplified to Traditional Chinese (Hong Kong)
 //   - "t2tw.json" - Traditional to Traditional Chinese (Taiwan)
 //   - "t2hk.json" - Traditional to Traditional Chinese (Hong Kong)
-func NewConverter(configFile string) (*Converter, error) {
-	mod, err := newModule()
+func NewConverter(configFile string, opts ...Option) (*Converter, error) {
+	return NewConverterContext(context.Background(), configFile, opts...)
+}
+
+// NewConverterContext is like NewConverter but uses ctx to bound compiling
+// the binary on first use, module instantiation and opening the
+// configuration. If ctx is done first, the returned error wraps ctx.Err().
+func NewConverterContext(ctx context.Context, configFile string, opts ...Option) (*Converter, error) {
+	return newConverter(ctx, defaultEngine, configFile, opts)
+}
+
+// newConverter creates a converter for configFile in engine e.
+func newConverter(ctx context.Context, e *Engine, configFile string, opts []Option) (*Converter, error) {
+	o := newOptions(opts)
+	o.engine = e
+	data := o.dataFS
+	root, err := mountConfig(o, configFile)
 	if err != nil {
-		return nil, fmt.Errorf("init module: %w", err)
+		return nil, err
 	}
+	mount := &swapFS{fs: root}
+	o.dataFS = mount
 
-	var handle uint32
-	if err := mod.call("opencc_open", &handle, configFile); err != nil {
+	mod, handle, err := openModule(ctx, o, configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Converter{
+		mod:            mod,
+		handle:         handle,
+		config:         configFile,
+		opts:           opts,
+		options:        o,
+		data:           data,
+		mount:          mount,
+		recycleAfter:   o.recycleAfter,
+		maxInputSize:   o.maxInputSize,
+		tracer:         o.tracer,
+		skipValidation: o.skipValidation,
+		keepBOM:        o.keepBOM,
+		normalizeNFC:   o.normalizeNFC,
+		preserve:       o.preserved(),
+	}
+	runtime.SetFinalizer(c, (*Converter).finalize)
+	e.countConverter(true)
+	return c, nil
+}
+
+// openModule instantiates a module and opens configFile in it.
+func openModule(ctx context.Context, o *options, configFile string) (*module, uint32, error) {
+	mod, err := o.engine.newModule(ctx, o)
+	if err != nil {
+		return nil, 0, fmt.Errorf("init module: %w", err)
+	}
+
+	handle, err := mod.open(ctx, configFile)
+	if err != nil {
 		mod.close()
-		return nil, fmt.Errorf("open converter: %w", err)
+		return nil, 0, err
+	}
+	return mod, handle, nil
+}
+
+// open opens configFile in m and returns its handle. Failures reported by
+// OpenCC are returned as a *ConversionError and leave m usable.
+func (m *module) open(ctx context.Context, configFile string) (uint32, error) {
+	var handle uint32
+	if err := m.call(ctx, "opencc_open", &handle, configFile); err != nil {
+		if excErr := exceptionError("open", configFile, ErrOpenFailed, err); excErr != nil {
+			return 0, excErr
+		}
+		return 0, fmt.Errorf("open converter: %w", err)
 	}
 
 	if handle == ^uint32(0) { // (opencc_t)-1
-		mod.close()
-		return nil, ErrInvalidConverter
+		return 0, &ConversionError{Op: "open", Config: configFile, Err: ErrOpenFailed}
+	}
+	return handle, nil
+}
+
+// mountConfig prepares o to open configFile, applying custom dictionaries,
+// and returns the filesystem to mount for it.
+func mountConfig(o *options, configFile string) (fs.FS, error) {
+	if err := o.applyCustomDicts(configFile); err != nil {
+		return nil, err
+	}
+	if err := checkConfig(o, configFile); err != nil {
+		return nil, err
+	}
+
+	root, err := o.root()
+	if err != nil {
+		return nil, fmt.Errorf("create data sub-filesystem: %w", err)
+	}
+	// Only mount the files the configuration needs
+	return configFS(root, configFile), nil
+}
+
+// finalize closes a converter that was garbage collected without being
+// closed, so a forgotten Close doesn't leak its module instance.
+func (c *Converter) finalize() {
+	getLogger().Warn("converter garbage collected without Close", "config", c.config)
+	if err := c.Close(); err != nil {
+		getLogger().Warn("error closing leaked converter", "config", c.config, "error", err)
 	}
+}
 
-	return &Converter{
-		mod:    mod,
-		handle: handle,
-	}, nil
+// checkConfig reports ErrConfigNotFound if configFile doesn't exist in the
+// filesystem mounted for the converter, so a typo doesn't surface as an
+// exception from deep inside OpenCC.
+func checkConfig(o *options, configFile string) error {
+	root, err := o.root()
+	if err != nil {
+		return fmt.Errorf("create data sub-filesystem: %w", err)
+	}
+
+	name := path.Clean(strings.TrimPrefix(configFile, "/"))
+	if info, err := fs.Stat(root, name); err != nil || info.IsDir() {
+		return &ConversionError{Op: "open", Config: configFile, Err: ErrConfigNotFound}
+	}
+	return nil
+}
+
+// NewConverterFromFS creates a converter for configFile using the OpenCC
+// configuration and dictionaries in fsys instead of the embedded ones.
+// configFile and the dictionaries it references are resolved against the
+// root of fsys.
+func NewConverterFromFS(fsys fs.FS, configFile string, opts ...Option) (*Converter, error) {
+	return NewConverter(configFile, append(opts, WithDataFS(fsys))...)
+}
+
+// NewConverterFromFile creates a converter for the OpenCC configuration file
+// at path. Dictionaries referenced by the configuration are looked up in the
+// same directory.
+func NewConverterFromFile(path string, opts ...Option) (*Converter, error) {
+	return NewConverterFromFS(os.DirFS(filepath.Dir(path)), filepath.Base(path), opts...)
+}
+
+// openConverters opens a converter for each of configs, with errors
+// prefixed by what. Every configuration is checked before any converter is
+// opened, and if one can't be opened the others are closed again.
+func openConverters(what string, configs []string) ([]*Converter, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("%s: no configurations", what)
+	}
+
+	o := newOptions(nil)
+	for _, config := range configs {
+		if err := checkConfig(o, config); err != nil {
+			return nil, err
+		}
+	}
+
+	converters := make([]*Converter, 0, len(configs))
+	for _, config := range configs {
+		c, err := NewConverter(config)
+		if err != nil {
+			for _, opened := range converters {
+				opened.Close()
+			}
+			return nil, err
+		}
+		converters = append(converters, c)
+	}
+	return converters, nil
+}
+
+// Clone creates an independent converter, with its own module instance,
+// for the same configuration and options as c.
+func (c *Converter) Clone() (*Converter, error) {
+	c.mu.Lock()
+	closed, config := c.invalid(), c.config
+	c.mu.Unlock()
+
+	if closed {
+		return nil, ErrInvalidConverter
+	}
+	return c.options.engine.NewConverter(config, c.opts...)
 }
 
 // Convert converts the input text using the converter
 func (c *Converter) Convert(input string) (string, error) {
-	if c.mod == nil || c.handle == ^uint32(0) {
-		return "", ErrInvalidConverter
-	}
+	return c.ConvertContext(context.Background(), input)
+}
 
+// ConvertContext converts the input text using the converter. If ctx is
+// done before the conversion finishes, the WASM call is interrupted and
+// ctx.Err() is returned. An interrupted converter can no longer be used
+// and should be closed.
+func (c *Converter) ConvertContext(ctx context.Context, input string) (string, error) {
 	var result string
-	if err := c.mod.call("opencc_convert", &result, c.handle, input); err != nil {
-		return "", fmt.Errorf("convert: %w", err)
+	if err := c.convert(ctx, &result, input); err != nil {
+		return "", err
 	}
+	return result, nil
+}
 
-	if result == "" {
-		return "", ErrConversionFailed
+// ConvertWithFallback is like Convert, but returns input unchanged along
+// with the error if the conversion fails, so callers that would rather show
+// unconverted text than none can log the error and carry on.
+func (c *Converter) ConvertWithFallback(input string) (string, error) {
+	result, err := c.Convert(input)
+	if err != nil {
+		return input, err
 	}
+	return result, nil
+}
 
+// ConvertBytes converts UTF-8 encoded input using the converter. It behaves
+// like Convert but skips the conversions between string and []byte.
+func (c *Converter) ConvertBytes(input []byte) ([]byte, error) {
+	var result []byte
+	if err := c.convert(context.Background(), &result, input); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
-// Close closes the converter and releases resources
-func (c *Converter) Close() error {
-	if c.mod == nil {
+// ConvertBatch converts each of inputs using the converter and returns the
+// results in the same order. Empty inputs are returned unchanged, and a
+// leading U+FEFF is converted rather than stripped, since the inputs are
+// pieces of text rather than documents. It stops at the first failure and
+// reports the index of the failing input.
+func (c *Converter) ConvertBatch(inputs []string) ([]string, error) {
+	results := make([]string, len(inputs))
+	for i, input := range inputs {
+		if input == "" {
+			continue
+		}
+
+		result, err := c.convertPart(input)
+		if err != nil {
+			return nil, fmt.Errorf("convert input %d: %w", i, err)
+		}
+		results[i] = result
+	}
+
+	return results, nil
+}
+
+// convert runs opencc_convert on input, a whole document, storing the
+// result in dest. A leading byte order mark is left out of the conversion,
+// and only added back to the result with WithKeepBOM.
+func (c *Converter) convert(ctx context.Context, dest, input any) error {
+	return c.convertText(ctx, dest, input, true)
+}
+
+// convertPart converts input, a piece of a document such as a line, a field
+// or a batch item. A leading U+FEFF there is a zero width no-break space
+// rather than a byte order mark, so it is converted along with the rest.
+func (c *Converter) convertPart(input string) (string, error) {
+	var result string
+	if err := c.convertText(context.Background(), &result, input, false); err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// convertText implements convert, and convertPart unless doc is set.
+func (c *Converter) convertText(ctx context.Context, dest, input any, doc bool) (err error) {
+	defer func() { recordMetrics(dest, input, err) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tracer != nil {
+		var end func(dest any, err error)
+		ctx, end = c.startSpan(ctx, input)
+		defer func() { end(dest, err) }()
+	}
+
+	if c.invalid() {
+		return ErrInvalidConverter
+	}
+	if err := c.checkInput(input); err != nil {
+		return err
+	}
+
+	text, hasBOM := c.prepare(input, doc)
+	keepBOM := hasBOM && c.keepBOM
+	if keepBOM && !writeBOM(dest) {
 		return nil
 	}
 
-	if c.handle != ^uint32(0) {
-		var result int32
-		if err := c.mod.call("opencc_close", &result, c.handle); err != nil {
-			// Log the error but continue with cleanup
-			fmt.Printf("Warning: error closing OpenCC converter: %v\n", err)
-		}
-		c.handle = ^uint32(0)
+	if c.preserve != nil {
+		err = c.convertPreserving(ctx, dest, text)
+	} else {
+		err = c.convertLocked(ctx, dest, text)
+	}
+	if err != nil {
+		return err
 	}
 
-	c.mod.close()
-	c.mod = nil
+	if keepBOM {
+		addBOM(dest)
+	}
+	c.countConversion(ctx)
 	return nil
 }
 
-// ConvertS2T converts Simplified Chinese to Traditional Chinese
-func ConvertS2T(input string) (string, error) {
-	mod, err := newModule()
+// prepare returns input the way convertText passes it to OpenCC, and
+// whether it started with a byte order mark, which only a document, doc,
+// has.
+func (c *Converter) prepare(input any, doc bool) (any, bool) {
+	text, hasBOM := input, false
+	if doc {
+		text, hasBOM = cutBOM(input)
+	}
+	if c.normalizeNFC {
+		text = normalizeNFC(text)
+	}
+	return text, hasBOM
+}
+
+// convertLocked calls opencc_convert, or opencc_convert_len if the binary
+// exports it. c.mu must be held.
+func (c *Converter) convertLocked(ctx context.Context, dest, input any) error {
+	var err error
+	if c.mod.convertLen {
+		err = c.mod.call(ctx, "opencc_convert_len", dest, c.handle, input, resultLen{})
+	} else {
+		err = c.mod.call(ctx, "opencc_convert", dest, c.handle, input)
+	}
 	if err != nil {
-		return "", fmt.Errorf("init module: %w", err)
+		oom := c.mod.allocFailed(err)
+		if c.mod.mod.IsClosed() {
+			// The runtime closed the module when ctx was done mid-call
+			c.handle = ^uint32(0)
+		} else if c.mod.trapped {
+			// A trap can leave the heap and OpenCC's state half updated, so
+			// don't trust this instance with another conversion
+			if rerr := c.recycle(ctx); rerr != nil {
+				getLogger().Warn("error recycling converter after a trap", "config", c.config, "error", rerr)
+				c.handle = ^uint32(0)
+			}
+		}
+		if excErr := exceptionError("convert", c.config, ErrConversionFailed, err); excErr != nil {
+			return excErr
+		}
+		if errors.Is(err, errNullResult) {
+			return &ConversionError{Op: "convert", Config: c.config, Err: ErrConversionFailed}
+		}
+		if oom {
+			return fmt.Errorf("convert: input of %d bytes: %w", inputLen(input), ErrOutOfMemory)
+		}
+		return fmt.Errorf("convert: %w", err)
 	}
-	defer mod.close()
 
-	var result string
-	if err := mod.call("opencc_s2t", &result, input); err != nil {
-		return "", fmt.Errorf("convert: %w", err)
+	return nil
+}
+
+// Close closes the converter and releases resources. All cleanup steps are
+// run even if one fails, and their errors are returned together. Calling
+// Close again does nothing.
+func (c *Converter) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.mod == nil {
+		return nil
 	}
+	runtime.SetFinalizer(c, nil)
+
+	err := c.closeModule()
+	c.mod = nil
+	c.options.engine.countConverter(false)
+	return err
+}
 
-	// Empty result is only an error if input was non-empty
-	if result == "" && input != "" {
-		return "", ErrConversionFailed
+// closeModule closes the handle and module instance of c. c.mu must be held.
+func (c *Converter) closeModule() error {
+	var errs []error
+	// Shutdown or Engine.Close may have closed the module already
+	if !c.invalid() {
+		if err := c.closeHandle(context.Background()); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	return result, nil
+	if err := c.mod.close(); err != nil {
+		errs = append(errs, fmt.Errorf("close module: %w", err))
+	}
+	return errors.Join(errs...)
 }
 
-// ConvertT2S converts Traditional Chinese to Simplified Chinese
-func ConvertT2S(input string) (string, error) {
-	mod, err := newModule()
+// closeHandle closes c's OpenCC handle, leaving the module open. c.mu must
+// be held.
+func (c *Converter) closeHandle(ctx context.Context) error {
+	var result int32
+	err := c.mod.call(ctx, "opencc_close", &result, c.handle)
+	c.handle = ^uint32(0)
 	if err != nil {
-		return "", fmt.Errorf("init module: %w", err)
+		return fmt.Errorf("close converter: %w", err)
+	}
+	if result != 0 {
+		return fmt.Errorf("close converter: opencc_close returned %d", result)
 	}
-	defer mod.close()
+	return nil
+}
 
-	var result string
-	if err := mod.call("opencc_t2s", &result, input); err != nil {
-		return "", fmt.Errorf("convert: %w", err)
+// checkInput rejects input larger than the converter's limit before any of
+// it is copied into WASM memory, then applies checkInput.
+func (c *Converter) checkInput(input any) error {
+	if n := inputLen(input); c.maxInputSize > 0 && n > c.maxInputSize {
+		return fmt.Errorf("%w: %d bytes exceeds the limit of %d", ErrInputTooLarge, n, c.maxInputSize)
 	}
+	return checkInput(input, !c.skipValidation)
+}
 
-	// Empty result is only an error if input was non-empty
-	if result == "" && input != "" {
-		return "", ErrConversionFailed
+// inputLen returns the length in bytes of a string or []byte input.
+func inputLen(input any) int {
+	switch v := input.(type) {
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
 	}
+	return 0
+}
 
-	return result, nil
+// checkInput rejects input OpenCC can't convert faithfully. Input is passed
+// as a C string, so anything after a NUL byte would be dropped, and OpenCC
+// expects valid UTF-8 if validate is set.
+func checkInput(input any, validate bool) error {
+	nul, invalid := -1, -1
+	switch v := input.(type) {
+	case string:
+		nul = strings.IndexByte(v, 0)
+		if validate && !utf8.ValidString(v) {
+			invalid = invalidUTF8(v)
+		}
+	case []byte:
+		nul = bytes.IndexByte(v, 0)
+		if validate && !utf8.Valid(v) {
+			invalid = invalidUTF8(string(v))
+		}
+	}
+
+	switch {
+	case invalid >= 0 && (nul < 0 || invalid < nul):
+		return &InputError{Offset: invalid, Reason: "invalid UTF-8"}
+	case nul >= 0:
+		return &InputError{Offset: nul, Reason: "NUL byte"}
+	}
+	return nil
+}
+
+// invalidUTF8 returns the offset of the first invalid UTF-8 sequence in s,
+// or -1 if there is none.
+func invalidUTF8(s string) int {
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// IsClosed reports whether the converter can no longer be used, either
+// because Close has been called, because a conversion was interrupted or
+// because its module was closed by Shutdown or Engine.Close. Methods called
+// on such a converter return ErrInvalidConverter.
+func (c *Converter) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.invalid()
+}
+
+// invalid reports whether the converter can no longer be used, marking its
+// handle invalid once its module has been closed from outside. c.mu must be
+// held.
+func (c *Converter) invalid() bool {
+	if c.mod == nil {
+		return true
+	}
+	if c.handle != ^uint32(0) && c.mod.mod.IsClosed() {
+		c.handle = ^uint32(0)
+	}
+	return c.handle == ^uint32(0)
 }
 
 // module wraps wazero module for OpenCC
 type module struct {
 	mod api.Module
+
+	// exception is set by __cxa_throw during a call
+	exception *cxxException
+
+	// trapped is set once a call into m is aborted by a trap or exception,
+	// after which its heap can't be trusted
+	trapped bool
+
+	// convertLen is set if the binary exports opencc_convert_len, looked
+	// up once when the module is instantiated
+	convertLen bool
+
+	// lenPtr is where exports taking a resultLen argument store the length
+	// of their result, allocated on first use
+	lenPtr uint32
 }
 
-var (
-	rtMu sync.Mutex
-	rt   wazero.Runtime
-	cm   wazero.CompiledModule
-)
+// resultLen is a call argument passing a pointer the export stores the
+// length of its result at, so the result is read without scanning for its
+// terminator.
+type resultLen struct{}
 
-func newModule() (*module, error) {
-	rtMu.Lock()
-	defer rtMu.Unlock()
+// moduleKey is the context key under which module.call passes the
+// calling module to host functions
+type moduleKey struct{}
 
-	if rt == nil {
-		rt = wazero.NewRuntime(context.Background())
-		if _, err := wasi_snapshot_preview1.Instantiate(context.Background(), rt); err != nil {
-			return nil, fmt.Errorf("instantiate wasi: %w", err)
-		}
+// cxxException is a C++ exception thrown inside the WASM binary
+type cxxException struct {
+	ptr uint32
+	msg string
+}
 
-		// Create env module for C++ runtime functions
-		envModuleBuilder := rt.NewHostModuleBuilder("env")
-
-		// C++ exception handling functions
-		envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
-			// __cxa_allocate_exception - allocate memory for exception
-			size := uint32(stack[0])
-			malloc := mod.ExportedFunction("malloc")
-			ret, _ := malloc.Call(ctx, uint64(size))
-			stack[0] = ret[0]
-		}), []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}).Export("__cxa_allocate_exception")
-
-		envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
-			// __cxa_throw - throw exception, try to get error info
-			exceptionPtr := uint32(stack[0])
-			fmt.Printf("C++ exception thrown - ptr: %v, type: %v, destructor: %v\n", stack[0], stack[1], stack[2])
-
-			// Try to read error string from memory if possible
-			mem := mod.Memory()
-			if mem != nil && exceptionPtr > 0 {
-				errorMsg := ""
-				for i := uint32(0); i < 256; i++ { // Read max 256 bytes
-					b, ok := mem.ReadByte(exceptionPtr + i)
-					if !ok || b == 0 {
-						break
-					}
-					if b >= 32 && b <= 126 { // Only printable ASCII
-						errorMsg += string(b)
-					}
-				}
-				if errorMsg != "" {
-					fmt.Printf("Exception message: %s\n", errorMsg)
-				}
-			}
+func (e *cxxException) Error() string {
+	if e.msg == "" {
+		return "OpenCC error: failed to load or process configuration"
+	}
+	return "OpenCC error: " + e.msg
+}
 
-			panic("OpenCC error: failed to load or process configuration")
-		}), []api.ValueType{api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{}).Export("__cxa_throw")
+// SetCacheDir sets a directory in which the compiled WASM binary is cached
+// between process runs, which saves recompiling it on startup. An empty dir
+// disables the cache. The directory defaults to the OPENCC_CACHE_DIR
+// environment variable. It takes effect the next time the runtime is
+// initialized, i.e. before the first conversion or after Shutdown.
+func SetCacheDir(dir string) {
+	defaultEngine.mu.Lock()
+	defer defaultEngine.mu.Unlock()
 
-		envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
-			// __cxa_free_exception - free exception memory
-			ptr := uint32(stack[0])
-			free := mod.ExportedFunction("free")
-			if _, err := free.Call(ctx, uint64(ptr)); err != nil {
-				fmt.Printf("Warning: error freeing exception memory: %v\n", err)
-			}
-		}), []api.ValueType{api.ValueTypeI32}, []api.ValueType{}).Export("__cxa_free_exception")
-
-		// Personality function for exception handling
-		envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
-			// Just return 0 to indicate we don't handle exceptions
-			stack[0] = 0
-		}), []api.ValueType{api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI64, api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}).Export("__gxx_personality_v0")
-
-		// Type info functions
-		envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
-			// __cxa_begin_catch - begin catching exception
-			// Return the exception pointer as-is (pass-through)
-			// stack[0] already contains the input, no assignment needed
-		}), []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}).Export("__cxa_begin_catch")
-
-		envModuleBuilder.NewFunctionBuilder().WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
-			// __cxa_end_catch - end catching exception (no-op)
-		}), []api.ValueType{}, []api.ValueType{}).Export("__cxa_end_catch")
-
-		_, err := envModuleBuilder.Instantiate(context.Background())
-		if err != nil {
-			return nil, fmt.Errorf("instantiate env module: %w", err)
-		}
+	defaultEngine.cacheDir = dir
+}
 
-		var err2 error
-		cm, err2 = rt.CompileModule(context.Background(), binary)
-		if err2 != nil {
-			return nil, fmt.Errorf("compile module: %w", err2)
-		}
+// SetMemoryLimitPages caps the linear memory of each converter at pages
+// 64 KiB pages, so huge inputs fail with ErrOutOfMemory instead of growing
+// memory without bound. Zero restores the default of 4 GiB. The dictionaries
+// alone take about 9 MiB for s2t.json, so the limit has to leave room for
+// them on top of the input and its conversion. Like SetCacheDir, it takes
+// effect the next time the runtime is initialized.
+func SetMemoryLimitPages(pages uint32) {
+	defaultEngine.mu.Lock()
+	defer defaultEngine.mu.Unlock()
+
+	defaultEngine.memoryLimitPages = pages
+}
+
+// Preload initializes the shared WASM runtime and compiles the embedded
+// binary, which otherwise happens on the first conversion. Servers can call
+// it during startup to fail fast and keep the compilation cost off the first
+// request. Calling it again after a successful Preload does nothing. If
+// ctx is done before the binary is compiled, Preload returns an error
+// wrapping ctx.Err(), so startup probes can bound it with a deadline.
+func Preload(ctx context.Context) error {
+	return defaultEngine.Preload(ctx)
+}
+
+// Stats returns counts of the converters created with NewConverter and the
+// other package-level constructors and helpers, so leaks show up as a
+// growing Live count. See Engine.Stats.
+func Stats() ConverterStats {
+	return defaultEngine.Stats()
+}
+
+// Shutdown closes the shared WASM runtime along with every module
+// instantiated from it and releases the compiled module. Converters created
+// before Shutdown can no longer be used. The next conversion initializes the
+// runtime again. Pools created with NewConverterPool drop such converters
+// when they are returned or handed out, and create new ones in their place.
+func Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, p := range defaultPools {
+		errs = append(errs, p.drain())
 	}
+	errs = append(errs, defaultEngine.Close(ctx))
+	return errors.Join(errs...)
+}
 
-	// Configure module with embedded file system access
-	// Create a sub-filesystem from the embedded data directory
-	dataSubFS, err := fs.Sub(dataFS, "data")
-	if err != nil {
-		return nil, fmt.Errorf("create data sub-filesystem: %w", err)
+// retry calls f up to attempts times until it succeeds or fails with an
+// error that retryable rejects, sleeping backoff before the first retry and
+// doubling it after each. Once ctx is done it stops and returns ctx.Err().
+func retry(ctx context.Context, attempts int, backoff time.Duration, retryable func(error) bool, f func() error) error {
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := f()
+		if err == nil {
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			// f failed because ctx was done
+			return ctxErr
+		}
+		if attempt >= attempts || !retryable(err) {
+			return err
+		}
+		getLogger().Warn("retrying after failure", "attempt", attempt, "error", err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		backoff *= 2
 	}
+}
 
-	config := wazero.NewModuleConfig().
-		WithFS(dataSubFS). // Mount embedded data directory as root
-		WithArgs("opencc").
-		WithName("opencc").
-		WithStdout(os.Stdout).
-		WithStderr(os.Stderr)
+// allocFailed reports whether err is a trap raised by operator new, which
+// aborts when malloc returns a null pointer because memory.grow failed.
+// wazero has no structured stack trace, so the frame is found by the debug
+// name it prints for the function.
+func (m *module) allocFailed(err error) bool {
+	fn := m.mod.ExportedFunction("_Znwm") // operator new(unsigned long)
+	if fn == nil || !m.trapped {
+		return false
+	}
+	return strings.Contains(err.Error(), "\n\t"+fn.Definition().DebugName()+"(")
+}
 
-	mod, err := rt.InstantiateModule(context.Background(), cm, config)
+// malloc allocates size bytes in the module. It returns the error of the
+// call itself, e.g. when the module was closed, and ErrOutOfMemory only when
+// malloc returned a null pointer.
+func (m *module) malloc(ctx context.Context, size uint32) (uint32, error) {
+	ret, err := m.mod.ExportedFunction("malloc").Call(ctx, uint64(size))
 	if err != nil {
-		return nil, fmt.Errorf("instantiate module: %w", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
+		m.trapped = true
+		return 0, fmt.Errorf("malloc: %w", err)
 	}
-
-	return &module{mod: mod}, nil
+	if len(ret) == 0 || ret[0] == 0 {
+		return 0, fmt.Errorf("allocate %d bytes: %w", size, ErrOutOfMemory)
+	}
+	return uint32(ret[0]), nil
 }
 
-func (m *module) malloc(size uint32) uint32 {
-	ret, _ := m.mod.ExportedFunction("malloc").Call(context.Background(), uint64(size))
-	return uint32(ret[0])
-}
+func (m *module) call(ctx context.Context, name string, dest any, args ...any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if exc, ok := r.(*cxxException); ok {
+				err = fmt.Errorf("call %s: %w", name, exc)
+			} else {
+				err = fmt.Errorf("call %s: panic: %v", name, r)
+			}
+		}
+	}()
 
-func (m *module) call(name string, dest any, args ...any) error {
 	fn := m.mod.ExportedFunction(name)
 	if fn == nil {
-		return fmt.Errorf("function %s not found", name)
+		return fmt.Errorf("function %s not found: %w", name, errors.ErrUnsupported)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("call %s: %w", name, err)
 	}
 
 	var params []uint64
 	var ptrsToFree []uint32
+	var sized bool
 
 	defer func() {
 		for _, ptr := range ptrsToFree {
-			if ptr != 0 {
-				if _, err := m.mod.ExportedFunction("free").Call(context.Background(), uint64(ptr)); err != nil {
-					// Log error but don't fail since this is cleanup
-					fmt.Printf("Warning: error freeing memory: %v\n", err)
-				}
-			}
+			m.free(ptr)
 		}
 	}()
 
 	for _, arg := range args {
 		switch v := arg.(type) {
 		case string:
-			ptr := makeString(m, v)
+			ptr, err := makeString(ctx, m, v)
+			if err != nil {
+				return fmt.Errorf("call %s: %w", name, err)
+			}
 			ptrsToFree = append(ptrsToFree, ptr)
 			params = append(params, uint64(ptr))
+		case []byte:
+			ptr, err := makeBytes(ctx, m, v)
+			if err != nil {
+				return fmt.Errorf("call %s: %w", name, err)
+			}
+			ptrsToFree = append(ptrsToFree, ptr)
+			params = append(params, uint64(ptr))
+		case resultLen:
+			if m.lenPtr == 0 {
+				ptr, err := m.malloc(ctx, 4)
+				if err != nil {
+					return fmt.Errorf("call %s: %w", name, err)
+				}
+				m.lenPtr = ptr
+			}
+			params = append(params, uint64(m.lenPtr))
+			sized = true
 		case uint32:
 			params = append(params, uint64(v))
 		case int32:
@@ -301,8 +771,17 @@ func (m *module) call(name string, dest any, args ...any) error {
 		}
 	}
 
-	ret, err := fn.Call(context.Background(), params...)
+	m.exception = nil
+	ret, err := fn.Call(context.WithValue(ctx, moduleKey{}, m), params...)
 	if err != nil {
+		m.trapped = ctx.Err() == nil
+		if exc := m.exception; exc != nil {
+			m.exception = nil
+			return fmt.Errorf("call %s: %w", name, exc)
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("call %s: %w", name, ctxErr)
+		}
 		return fmt.Errorf("call %s: %w", name, err)
 	}
 
@@ -314,14 +793,26 @@ func (m *module) call(name string, dest any, args ...any) error {
 	case *string:
 		ptr := uint32(ret[0])
 		if ptr == 0 {
-			*d = ""
-		} else {
-			*d = readString(m, ptr)
-			// Free the returned string
-			if _, err := m.mod.ExportedFunction("opencc_convert_free").Call(context.Background(), uint64(ptr)); err != nil {
-				fmt.Printf("Warning: error freeing converted string: %v\n", err)
-			}
+			return fmt.Errorf("call %s: %w", name, errNullResult)
+		}
+		*d = string(m.result(ptr, sized))
+		m.freeResult(ptr)
+	case *[]byte:
+		ptr := uint32(ret[0])
+		if ptr == 0 {
+			return fmt.Errorf("call %s: %w", name, errNullResult)
+		}
+		*d = bytes.Clone(m.result(ptr, sized))
+		m.freeResult(ptr)
+	case *writerDest:
+		ptr := uint32(ret[0])
+		if ptr == 0 {
+			return fmt.Errorf("call %s: %w", name, errNullResult)
 		}
+		n, err := d.w.Write(m.result(ptr, sized))
+		d.n += n
+		d.err = err
+		m.freeResult(ptr)
 	case *uint32:
 		*d = uint32(ret[0])
 	case *int32:
@@ -333,41 +824,144 @@ func (m *module) call(name string, dest any, args ...any) error {
 	return nil
 }
 
-func (m *module) close() {
-	if m.mod != nil {
-		m.mod.Close(context.Background())
+// writerDest is a call destination that writes a returned string straight
+// from module memory to w, recording the result of the write.
+type writerDest struct {
+	w   io.Writer
+	n   int
+	err error
+}
+
+func (m *module) close() error {
+	if m.mod == nil {
+		return nil
+	}
+	return m.mod.Close(context.Background())
+}
+
+// freeResult frees a string returned by the OpenCC conversion functions.
+func (m *module) freeResult(ptr uint32) {
+	if _, err := m.mod.ExportedFunction("opencc_convert_free").Call(context.Background(), uint64(ptr)); err != nil {
+		getLogger().Warn("error freeing converted string", "error", err)
+	}
+}
+
+func (m *module) free(ptr uint32) {
+	if _, err := m.mod.ExportedFunction("free").Call(context.Background(), uint64(ptr)); err != nil {
+		// Log error but don't fail since this is cleanup
+		getLogger().Warn("error freeing memory", "error", err)
 	}
 }
 
-func makeString(m *module, s string) uint32 {
+func makeBytes(ctx context.Context, m *module, b []byte) (uint32, error) {
+	size := uint32(len(b) + 1)
+	ptr, err := m.malloc(ctx, size)
+	if err != nil {
+		return 0, err
+	}
+
+	mem := m.mod.Memory()
+	if !mem.Write(ptr, b) || !mem.WriteByte(ptr+uint32(len(b)), 0) {
+		m.free(ptr)
+		return 0, fmt.Errorf("write %d bytes at %#x: out of range", size, ptr)
+	}
+
+	return ptr, nil
+}
+
+func makeString(ctx context.Context, m *module, s string) (uint32, error) {
 	size := uint32(len(s) + 1)
-	ptr := m.malloc(size)
-	if ptr == 0 {
-		return 0
+	ptr, err := m.malloc(ctx, size)
+	if err != nil {
+		return 0, err
 	}
 
-	if !m.mod.Memory().Write(ptr, append([]byte(s), 0)) {
-		return 0
+	// Write s straight from the string rather than through a []byte copy
+	mem := m.mod.Memory()
+	if !mem.WriteString(ptr, s) || !mem.WriteByte(ptr+uint32(len(s)), 0) {
+		m.free(ptr)
+		return 0, fmt.Errorf("write %d bytes at %#x: out of range", size, ptr)
 	}
 
-	return ptr
+	return ptr, nil
 }
 
-func readString(m *module, ptr uint32) string {
-	if ptr == 0 {
+// readException extracts the message of an OpenCC exception object. OpenCC
+// exceptions hold their message in a std::string following the vtable
+// pointer, which libc++ lays out on wasm32 either as {data, size, cap} with
+// the high bit of cap set, or inline with the size in the last byte.
+func readException(mem api.Memory, ptr uint32) string {
+	if mem == nil || ptr == 0 {
 		return ""
 	}
 
-	mem := m.mod.Memory()
-	var result []byte
-	for {
-		b, ok := mem.ReadByte(ptr)
-		if !ok || b == 0 {
-			break
+	const maxLen = 256
+	str := ptr + 4
+	last, ok := mem.ReadByte(str + 11)
+	if !ok {
+		return ""
+	}
+
+	var data []byte
+	if last&0x80 != 0 {
+		dataPtr, ok1 := mem.ReadUint32Le(str)
+		size, ok2 := mem.ReadUint32Le(str + 4)
+		if !ok1 || !ok2 {
+			return ""
+		}
+		data, ok = mem.Read(dataPtr, min(size, maxLen))
+	} else {
+		data, ok = mem.Read(str, min(uint32(last&0x7f), 11))
+	}
+	if !ok {
+		return ""
+	}
+
+	// Only keep printable ASCII
+	msg := make([]byte, 0, len(data))
+	for _, b := range data {
+		if b >= 32 && b <= 126 {
+			msg = append(msg, b)
 		}
-		result = append(result, b)
-		ptr++
+	}
+	return string(msg)
+}
+
+// result returns a view of the string an export returned at ptr, using the
+// length it stored if sized is set. The view is only valid until the next
+// call into the module.
+func (m *module) result(ptr uint32, sized bool) []byte {
+	if !sized {
+		return cstring(m, ptr)
+	}
+	mem := m.mod.Memory()
+	n, ok := mem.ReadUint32Le(m.lenPtr)
+	if !ok {
+		return nil
+	}
+	view, ok := mem.Read(ptr, n)
+	if !ok {
+		return nil
+	}
+	return view
+}
+
+// cstring returns a view of the NUL-terminated string at ptr in module
+// memory. The view is only valid until the next call into the module.
+func cstring(m *module, ptr uint32) []byte {
+	if ptr == 0 {
+		return nil
 	}
 
-	return string(result)
+	// Memory.Read doesn't copy, so scan everything up to the end of memory
+	// for the terminator in one go
+	mem := m.mod.Memory()
+	view, ok := mem.Read(ptr, mem.Size()-min(ptr, mem.Size()))
+	if !ok {
+		return nil
+	}
+	if i := bytes.IndexByte(view, 0); i >= 0 {
+		view = view[:i]
+	}
+	return view
 }
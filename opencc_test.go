@@ -1,7 +1,22 @@
 package opencc
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/sys"
 )
 
 func TestConvertS2T(t *testing.T) {
@@ -123,3 +138,1124 @@ func BenchmarkConverter(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkConvertReuse compares converting with a fresh converter per call,
+// a reused converter, a pool and the package-level helper, which should be
+// as fast as the pool.
+func BenchmarkConvertReuse(b *testing.B) {
+	input := "这是一个很长的测试文本，用来测试转换性能。包含了很多常用的汉字。"
+	if err := Preload(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer converter.Close()
+	pool := NewConverterPool("s2t.json")
+	defer pool.Close()
+
+	benchmarks := []struct {
+		name    string
+		convert func() (string, error)
+	}{
+		{"Fresh", func() (string, error) {
+			c, err := NewConverter("s2t.json")
+			if err != nil {
+				return "", err
+			}
+			defer c.Close()
+			return c.Convert(input)
+		}},
+		{"Converter", func() (string, error) { return converter.Convert(input) }},
+		{"Pool", func() (string, error) { return pool.Convert(input) }},
+		{"ConvertS2T", func() (string, error) { return ConvertS2T(input) }},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := bm.convert(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestHelpersReuseConverters guards against the helpers creating a
+// converter per call, which is orders of magnitude slower than reusing one;
+// see BenchmarkConvertReuse.
+func TestHelpersReuseConverters(t *testing.T) {
+	helpers := map[string]func(string) (string, error){
+		"ConvertS2T": ConvertS2T,
+		"ConvertT2S": ConvertT2S,
+		"Convert":    func(input string) (string, error) { return Convert("s2t", input) },
+	}
+
+	for name, convert := range helpers {
+		// The first call may create the pooled converter
+		if _, err := convert("简体字"); err != nil {
+			t.Fatalf("%s() error = %v", name, err)
+		}
+		before := Stats().Created
+		for i := 0; i < 10; i++ {
+			if _, err := convert("简体字"); err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+		}
+		if created := Stats().Created - before; created != 0 {
+			t.Errorf("%s() created %d converters in 10 sequential calls, want 0", name, created)
+		}
+	}
+}
+
+func TestConverterContext(t *testing.T) {
+	converter, err := NewConverterContext(context.Background(), "s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverterContext() error = %v", err)
+	}
+	defer converter.Close()
+
+	result, err := converter.ConvertContext(context.Background(), "简体字")
+	if err != nil {
+		t.Fatalf("ConvertContext() error = %v", err)
+	}
+	if result != "簡體字" {
+		t.Errorf("ConvertContext() = %v, want %v", result, "簡體字")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := converter.ConvertContext(ctx, "简体字"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ConvertContext() error = %v, want %v", err, context.Canceled)
+	}
+
+	// A context done before the call leaves the converter usable
+	if converter.IsClosed() {
+		t.Error("IsClosed() after ConvertContext() with a canceled context = true, want false")
+	}
+	if result, err := converter.Convert("简体字"); err != nil || result != "簡體字" {
+		t.Errorf("Convert() after canceled ConvertContext() = %q, %v, want %q, nil", result, err, "簡體字")
+	}
+}
+
+func TestNewConverterContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	converter, err := NewConverterContext(ctx, "s2t.json")
+	if err == nil {
+		converter.Close()
+		t.Fatal("NewConverterContext() error = nil, want non-nil")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("NewConverterContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestNewConverterMissingConfig(t *testing.T) {
+	converter, err := NewConverter("does-not-exist.json")
+	if err == nil {
+		converter.Close()
+		t.Fatal("NewConverter() error = nil, want non-nil")
+	}
+	if !errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("NewConverter() error = %v, want %v", err, ErrConfigNotFound)
+	}
+}
+
+func TestConvertS2TConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				result, err := ConvertS2T("这是一个测试")
+				if err != nil {
+					t.Errorf("ConvertS2T() error = %v", err)
+					return
+				}
+				if result != "這是一個測試" {
+					t.Errorf("ConvertS2T() = %v, want %v", result, "這是一個測試")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkNewModule(b *testing.B) {
+	// Pay the one-time runtime initialization up front
+	mod, err := defaultEngine.newModule(context.Background(), newOptions(nil))
+	if err != nil {
+		b.Fatal(err)
+	}
+	mod.close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mod, err := defaultEngine.newModule(context.Background(), newOptions(nil))
+		if err != nil {
+			b.Fatal(err)
+		}
+		mod.close()
+	}
+}
+
+// BenchmarkNewConverterParallel creates converters from several goroutines.
+// Only the lazy runtime initialization takes the engine lock, so instantiation scales
+// with GOMAXPROCS instead of serializing; compare -cpu 1,4.
+func BenchmarkNewConverterParallel(b *testing.B) {
+	if err := Preload(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			converter, err := NewConverter("s2t.json")
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			converter.Close()
+		}
+	})
+}
+
+func TestShutdown(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	if _, err := ConvertS2T("简体字"); err != nil {
+		t.Fatalf("ConvertS2T() error = %v", err)
+	}
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown() error = %v", err)
+	}
+
+	if _, err := converter.Convert("简体字"); err == nil {
+		t.Error("Convert() after Shutdown() error = nil, want non-nil")
+	} else if errors.Is(err, ErrOutOfMemory) {
+		t.Errorf("Convert() after Shutdown() error = %v, want not %v", err, ErrOutOfMemory)
+	}
+
+	result, err := ConvertS2T("简体字")
+	if err != nil {
+		t.Fatalf("ConvertS2T() after Shutdown() error = %v", err)
+	}
+	if result != "簡體字" {
+		t.Errorf("ConvertS2T() after Shutdown() = %v, want %v", result, "簡體字")
+	}
+}
+
+func TestNewConverterFromFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"s2t.json", "STPhrases.ocd2", "STCharacters.ocd2"} {
+		data, err := readEmbedded(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	converter, err := NewConverterFromFile(filepath.Join(dir, "s2t.json"))
+	if err != nil {
+		t.Fatalf("NewConverterFromFile() error = %v", err)
+	}
+	defer converter.Close()
+
+	result, err := converter.Convert("简体字")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result != "簡體字" {
+		t.Errorf("Convert() = %v, want %v", result, "簡體字")
+	}
+}
+
+func TestNewConverterFromFSMissingDict(t *testing.T) {
+	converter, err := NewConverterFromFS(missingDictFS(t), "s2t.json")
+	if err == nil {
+		converter.Close()
+		t.Fatal("NewConverterFromFS() error = nil, want non-nil")
+	}
+
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Fatalf("NewConverterFromFS() error = %v, want *ConversionError", err)
+	}
+	if !strings.Contains(convErr.Message, "STPhrases.ocd2") {
+		t.Errorf("Message = %q, want it to name the missing dictionary", convErr.Message)
+	}
+}
+
+func TestConvertWithFallback(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{"Converted", "简体字", "簡體字", nil},
+		{"Empty", "", "", nil},
+		{"InvalidInput", "简体\xff字", "简体\xff字", ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := converter.ConvertWithFallback(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ConvertWithFallback() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ConvertWithFallback() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertBytes(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	for _, input := range []string{"简体字", "这是一个测试", "mixed 中文 text"} {
+		want, err := converter.Convert(input)
+		if err != nil {
+			t.Fatalf("Convert(%q) error = %v", input, err)
+		}
+		got, err := converter.ConvertBytes([]byte(input))
+		if err != nil {
+			t.Fatalf("ConvertBytes(%q) error = %v", input, err)
+		}
+		if string(got) != want {
+			t.Errorf("ConvertBytes(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestConvertBatch(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	inputs := []string{"简体字", "", "这是一个测试", "转换"}
+	expected := []string{"簡體字", "", "這是一個測試", "轉換"}
+
+	results, err := converter.ConvertBatch(inputs)
+	if err != nil {
+		t.Fatalf("ConvertBatch() error = %v", err)
+	}
+	if !slices.Equal(results, expected) {
+		t.Errorf("ConvertBatch() = %v, want %v", results, expected)
+	}
+
+	converter.Close()
+	if _, err := converter.ConvertBatch(inputs); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("ConvertBatch() after Close() error = %v, want %v", err, ErrInvalidConverter)
+	} else if !strings.Contains(err.Error(), "input 0") {
+		t.Errorf("ConvertBatch() error = %v, want it to name the failing index", err)
+	}
+}
+
+func TestConverterConcurrent(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	inputs := []string{"简体字", "这是一个测试", "转换"}
+	expected := []string{"簡體字", "這是一個測試", "轉換"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			input, want := inputs[i%len(inputs)], expected[i%len(expected)]
+			result, err := converter.Convert(input)
+			if err != nil {
+				t.Errorf("Convert(%q) error = %v", input, err)
+				return
+			}
+			if result != want {
+				t.Errorf("Convert(%q) = %q, want %q", input, result, want)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestPreload(t *testing.T) {
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := Preload(context.Background()); err != nil {
+			t.Fatalf("Preload() error = %v", err)
+		}
+	}
+
+	if _, err := ConvertS2T("简体字"); err != nil {
+		t.Fatalf("ConvertS2T() after Preload() error = %v", err)
+	}
+}
+
+func TestPreloadDeadline(t *testing.T) {
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	if err := Preload(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Preload() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, err := NewConverterContext(ctx, "s2t.json"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("NewConverterContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// A timed out Preload leaves nothing behind
+	if err := Preload(context.Background()); err != nil {
+		t.Fatalf("Preload() error = %v", err)
+	}
+	if _, err := NewConverterContext(ctx, "s2t.json"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("NewConverterContext() after Preload() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, err := ConvertS2T("简体字"); err != nil {
+		t.Fatalf("ConvertS2T() after Preload() error = %v", err)
+	}
+}
+
+func TestSetCacheDir(t *testing.T) {
+	dir := t.TempDir()
+	SetCacheDir(dir)
+	defer func() {
+		SetCacheDir("")
+		Shutdown(context.Background())
+	}()
+
+	for i := 0; i < 2; i++ {
+		if err := Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+		start := time.Now()
+		if err := Preload(context.Background()); err != nil {
+			t.Fatalf("Preload() error = %v", err)
+		}
+		t.Logf("Preload() run %d took %v", i+1, time.Since(start))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Error("cache directory is empty after Preload()")
+	}
+
+	if _, err := ConvertS2T("简体字"); err != nil {
+		t.Fatalf("ConvertS2T() with cache error = %v", err)
+	}
+}
+
+func BenchmarkMakeString(b *testing.B) {
+	mod, err := defaultEngine.newModule(context.Background(), newOptions(nil))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer mod.close()
+
+	input := strings.Repeat("這是一個測試", 1024)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ptr, err := makeString(context.Background(), mod, input)
+		if err != nil {
+			b.Fatal(err)
+		}
+		mod.free(ptr)
+	}
+}
+
+func BenchmarkReadString(b *testing.B) {
+	mod, err := defaultEngine.newModule(context.Background(), newOptions(nil))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer mod.close()
+
+	input := strings.Repeat("這是一個測試", 1024)
+	ptr, err := makeString(context.Background(), mod, input)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer mod.free(ptr)
+
+	if mod.lenPtr, err = mod.malloc(context.Background(), 4); err != nil {
+		b.Fatal(err)
+	}
+	mod.mod.Memory().WriteUint32Le(mod.lenPtr, uint32(len(input)))
+
+	for name, sized := range map[string]bool{"Terminated": false, "Sized": true} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = string(mod.result(ptr, sized))
+			}
+		})
+	}
+}
+
+func TestModuleResult(t *testing.T) {
+	mod, err := defaultEngine.newModule(context.Background(), newOptions(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mod.close()
+
+	// Without a terminator where the length says the result ends, only a
+	// sized read stops there
+	ptr, err := mod.malloc(context.Background(), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem := mod.mod.Memory()
+	mem.Write(ptr, []byte("漢字abc\x00"))
+	if mod.lenPtr, err = mod.malloc(context.Background(), 4); err != nil {
+		t.Fatal(err)
+	}
+	mem.WriteUint32Le(mod.lenPtr, uint32(len("漢字")))
+
+	tests := []struct {
+		name  string
+		sized bool
+		want  string
+	}{
+		{"Terminated", false, "漢字abc"},
+		{"Sized", true, "漢字"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(mod.result(ptr, tt.sized)); got != tt.want {
+				t.Errorf("result() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	mem.WriteUint32Le(mod.lenPtr, 0)
+	if got := mod.result(ptr, true); len(got) != 0 {
+		t.Errorf("result() with length 0 = %q, want empty", got)
+	}
+}
+
+func TestNewConverterConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			converter, err := NewConverter("s2t.json")
+			if err != nil {
+				t.Errorf("NewConverter() error = %v", err)
+				return
+			}
+			defer converter.Close()
+
+			if result, err := converter.Convert("简体字"); err != nil || result != "簡體字" {
+				t.Errorf("Convert() = %q, %v, want %q, nil", result, err, "簡體字")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConverterClose(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	if err := converter.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := converter.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+
+	converter, err = NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	// Shutdown closed the module, so there is nothing left to fail
+	if err := converter.Close(); err != nil {
+		t.Errorf("Close() after Shutdown() error = %v, want nil", err)
+	}
+	if !converter.IsClosed() {
+		t.Error("Close() after Shutdown() left the converter open")
+	}
+}
+
+func TestConverterFinalizer(t *testing.T) {
+	var buf lockedBuffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer SetLogger(nil)
+
+	func() {
+		if _, err := NewConverter("s2t.json"); err != nil {
+			t.Fatalf("NewConverter() error = %v", err)
+		}
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(buf.String(), "garbage collected without Close") {
+		if time.Now().After(deadline) {
+			t.Fatal("leaked converter was not finalized")
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if strings.Contains(buf.String(), "error closing leaked converter") {
+		t.Errorf("log output = %q, want a clean close", buf.String())
+	}
+}
+
+// lockedBuffer is a bytes.Buffer that is safe to write from finalizers.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestConverterUseAfterClose(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	if converter.IsClosed() {
+		t.Fatal("IsClosed() = true for an open converter")
+	}
+	if err := converter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !converter.IsClosed() {
+		t.Fatal("IsClosed() = false after Close()")
+	}
+
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.txt")
+	if err := os.WriteFile(inPath, []byte("简体字"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"Convert", func() error {
+			_, err := converter.Convert("简体字")
+			return err
+		}},
+		{"ConvertContext", func() error {
+			_, err := converter.ConvertContext(context.Background(), "简体字")
+			return err
+		}},
+		{"ConvertBytes", func() error {
+			_, err := converter.ConvertBytes([]byte("简体字"))
+			return err
+		}},
+		{"ConvertBatch", func() error {
+			_, err := converter.ConvertBatch([]string{"简体字"})
+			return err
+		}},
+		{"ConvertStream", func() error {
+			return converter.ConvertStream(strings.NewReader("简体字"), io.Discard)
+		}},
+		{"ConvertFile", func() error {
+			return converter.ConvertFile(inPath, filepath.Join(dir, "out.txt"))
+		}},
+		{"Transform", func() error {
+			_, _, err := NewTransformer(converter).Transform(make([]byte, 64), []byte("简体字"), true)
+			return err
+		}},
+		{"InvalidInput", func() error {
+			_, err := converter.Convert("\xff")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrInvalidConverter) {
+				t.Errorf("%s() after Close() error = %v, want %v", tt.name, err, ErrInvalidConverter)
+			}
+		})
+	}
+}
+
+func TestConverterClone(t *testing.T) {
+	root, err := dataSubFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	converter, err := NewConverterFromFS(root, "s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverterFromFS() error = %v", err)
+	}
+
+	clone, err := converter.Clone()
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	defer clone.Close()
+
+	if err := converter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	result, err := clone.Convert("简体字")
+	if err != nil {
+		t.Fatalf("Convert() on clone error = %v", err)
+	}
+	if result != "簡體字" {
+		t.Errorf("Convert() on clone = %q, want %q", result, "簡體字")
+	}
+
+	if _, err := converter.Clone(); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("Clone() after Close() error = %v, want %v", err, ErrInvalidConverter)
+	}
+}
+
+func TestConvertSupplementaryPlane(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	// U+20000 and U+2A6A5 are CJK Extension B characters, 4 bytes in UTF-8
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"𠀀", "𠀀"},
+		{"𠀀简体𪚥", "𠀀簡體𪚥"},
+		{"简体𠀀", "簡體𠀀"},
+		{strings.Repeat("𠀀简", 1000), strings.Repeat("𠀀簡", 1000)},
+	}
+
+	for _, tt := range tests {
+		got, err := converter.Convert(tt.input)
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Convert(%.20q) = %.20q, want %.20q", tt.input, got, tt.want)
+		}
+
+		gotBytes, err := converter.ConvertBytes([]byte(tt.input))
+		if err != nil {
+			t.Fatalf("ConvertBytes() error = %v", err)
+		}
+		if string(gotBytes) != tt.want {
+			t.Errorf("ConvertBytes(%.20q) = %.20q, want %.20q", tt.input, gotBytes, tt.want)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errTransient := errors.New("transient")
+
+	tests := []struct {
+		name      string
+		attempts  int
+		failures  int
+		wantCalls int
+		wantErr   error
+	}{
+		{"success", 3, 0, 1, nil},
+		{"recovers", 3, 2, 3, nil},
+		{"gives up", 3, 5, 3, errTransient},
+		{"no retries", 1, 5, 1, errTransient},
+		{"zero attempts", 0, 5, 1, errTransient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retry(context.Background(), tt.attempts, time.Microsecond, retryAll, func() error {
+				calls++
+				if calls <= tt.failures {
+					return errTransient
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("retry() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("retry() called f %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+
+	t.Run("context done during backoff", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		err := retry(ctx, 3, time.Hour, retryAll, func() error { return errTransient })
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("retry() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		calls := 0
+		err := retry(context.Background(), 3, time.Microsecond, func(error) bool { return false }, func() error {
+			calls++
+			return errTransient
+		})
+		if !errors.Is(err, errTransient) || calls != 1 {
+			t.Errorf("retry() = %v after %d calls, want %v after 1", err, calls, errTransient)
+		}
+	})
+}
+
+func retryAll(error) bool { return true }
+
+// Modules that fail to instantiate: missingImportWasm imports env.f, and
+// trapStartWasm and trapExportedStartWasm trap in a start section and in an
+// exported _start function.
+var (
+	missingImportWasm = []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x04, 0x01, 0x60, 0x00, 0x00,
+		0x02, 0x09, 0x01, 0x03, 'e', 'n', 'v', 0x01, 'f', 0x00, 0x00,
+	}
+	trapStartWasm = []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x04, 0x01, 0x60, 0x00, 0x00,
+		0x03, 0x02, 0x01, 0x00,
+		0x08, 0x01, 0x00,
+		0x0a, 0x05, 0x01, 0x03, 0x00, 0x00, 0x0b,
+	}
+	trapExportedStartWasm = []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x04, 0x01, 0x60, 0x00, 0x00,
+		0x03, 0x02, 0x01, 0x00,
+		0x07, 0x0a, 0x01, 0x06, '_', 's', 't', 'a', 'r', 't', 0x00, 0x00,
+		0x0a, 0x05, 0x01, 0x03, 0x00, 0x00, 0x0b,
+	}
+)
+
+func TestRetryableInstantiate(t *testing.T) {
+	ctx := context.Background()
+	instantiate := func(wasm []byte, closed bool) error {
+		r := wazero.NewRuntime(ctx)
+		defer r.Close(ctx)
+		if closed {
+			r.Close(ctx)
+		}
+		_, err := r.Instantiate(ctx, wasm)
+		if err == nil {
+			t.Fatal("Instantiate() error = nil, want non-nil")
+		}
+		return err
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"missing import", instantiate(missingImportWasm, false), false},
+		{"closed runtime", instantiate(trapStartWasm, true), false},
+		{"exit", sys.NewExitError(1), false},
+		{"start section trap", instantiate(trapStartWasm, false), true},
+		{"start function trap", instantiate(trapExportedStartWasm, false), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableInstantiate(tt.err); got != tt.want {
+				t.Errorf("retryableInstantiate(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertEmptyResult(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	for _, input := range []string{"", " ", "\n", " \t\r\n"} {
+		got, err := converter.Convert(input)
+		if err != nil || got != input {
+			t.Errorf("Convert(%q) = %q, %v, want %q, nil", input, got, err, input)
+		}
+
+		b, err := converter.ConvertBytes([]byte(input))
+		if err != nil || string(b) != input {
+			t.Errorf("ConvertBytes(%q) = %q, %v, want %q, nil", input, b, err, input)
+		}
+
+		var buf strings.Builder
+		n, err := converter.ConvertTo(&buf, input)
+		if err != nil || n != len(input) || buf.String() != input {
+			t.Errorf("ConvertTo(%q) = %d, %v, wrote %q, want %d, nil", input, n, err, buf.String(), len(input))
+		}
+	}
+}
+
+// allocFailWasm is a module with a single page of memory whose malloc fails
+// for anything larger, and whose opencc_convert traps if it is ever called.
+var allocFailWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32) -> i32, (i32) -> (), (i32, i32) -> i32
+	0x01, 0x10, 0x03,
+	0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x01, 0x7f, 0x00,
+	0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7f,
+	// Functions: malloc, free, opencc_convert
+	0x03, 0x04, 0x03, 0x00, 0x01, 0x02,
+	// Memory: one page, at most one page
+	0x05, 0x04, 0x01, 0x01, 0x01, 0x01,
+	// Exports: memory, malloc, free, opencc_convert
+	0x07, 0x2b, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x06, 'm', 'a', 'l', 'l', 'o', 'c', 0x00, 0x00,
+	0x04, 'f', 'r', 'e', 'e', 0x00, 0x01,
+	0x0e, 'o', 'p', 'e', 'n', 'c', 'c', '_', 'c', 'o', 'n', 'v', 'e', 'r', 't', 0x00, 0x02,
+	// Code
+	0x0a, 0x1a, 0x03,
+	// malloc: size > 65536 ? 0 : 16
+	0x11, 0x00, 0x20, 0x00, 0x41, 0x80, 0x80, 0x04, 0x4b, 0x04, 0x7f, 0x41, 0x00, 0x05, 0x41, 0x10, 0x0b, 0x0b,
+	// free: nothing
+	0x02, 0x00, 0x0b,
+	// opencc_convert: unreachable
+	0x03, 0x00, 0x00, 0x0b,
+}
+
+// sizedWasm is stubWasm with an opencc_convert_len that stores a length of
+// 2 and returns its input unchanged.
+var sizedWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32) -> i32, (i32) -> (), (i32, i32) -> i32,
+	// (i32, i32, i32) -> i32
+	0x01, 0x17, 0x04,
+	0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x01, 0x7f, 0x00,
+	0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7f,
+	0x60, 0x03, 0x7f, 0x7f, 0x7f, 0x01, 0x7f,
+	// Functions: malloc, free, opencc_open, opencc_convert,
+	// opencc_convert_free, opencc_close, opencc_convert_len
+	0x03, 0x08, 0x07, 0x00, 0x01, 0x00, 0x02, 0x01, 0x00, 0x03,
+	// Memory: one page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Globals: the next free address, starting at 1024
+	0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b,
+	// Exports: memory and the functions
+	0x07, 0x73, 0x08,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x06, 'm', 'a', 'l', 'l', 'o', 'c', 0x00, 0x00,
+	0x04, 'f', 'r', 'e', 'e', 0x00, 0x01,
+	0x0b, 'o', 'p', 'e', 'n', 'c', 'c', '_', 'o', 'p', 'e', 'n', 0x00, 0x02,
+	0x0e, 'o', 'p', 'e', 'n', 'c', 'c', '_', 'c', 'o', 'n', 'v', 'e', 'r', 't', 0x00, 0x03,
+	0x13, 'o', 'p', 'e', 'n', 'c', 'c', '_', 'c', 'o', 'n', 'v', 'e', 'r', 't', '_', 'f', 'r', 'e', 'e', 0x00, 0x04,
+	0x0c, 'o', 'p', 'e', 'n', 'c', 'c', '_', 'c', 'l', 'o', 's', 'e', 0x00, 0x05,
+	0x12, 'o', 'p', 'e', 'n', 'c', 'c', '_', 'c', 'o', 'n', 'v', 'e', 'r', 't', '_', 'l', 'e', 'n', 0x00, 0x06,
+	// Code
+	0x0a, 0x2e, 0x07,
+	// malloc: return next, next += size
+	0x0b, 0x00, 0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x0b,
+	// free: nothing
+	0x02, 0x00, 0x0b,
+	// opencc_open: return handle 1
+	0x04, 0x00, 0x41, 0x01, 0x0b,
+	// opencc_convert: return input
+	0x04, 0x00, 0x20, 0x01, 0x0b,
+	// opencc_convert_free: nothing
+	0x02, 0x00, 0x0b,
+	// opencc_close: return 0
+	0x04, 0x00, 0x41, 0x00, 0x0b,
+	// opencc_convert_len: *length = 2, return input
+	0x0b, 0x00, 0x20, 0x02, 0x41, 0x02, 0x36, 0x02, 0x00, 0x20, 0x01, 0x0b,
+}
+
+func TestCallSizedResult(t *testing.T) {
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	mod, err := r.Instantiate(ctx, sizedWasm)
+	if err != nil {
+		t.Fatalf("Instantiate() error = %v", err)
+	}
+	m := &module{mod: mod}
+
+	// The result is read up to the length the module stored, not up to the
+	// terminator
+	var s string
+	if err := m.call(ctx, "opencc_convert_len", &s, uint32(1), "abc", resultLen{}); err != nil || s != "ab" {
+		t.Errorf("call() with *string = %q, %v, want %q, nil", s, err, "ab")
+	}
+	var b []byte
+	if err := m.call(ctx, "opencc_convert_len", &b, uint32(1), []byte("xyz"), resultLen{}); err != nil || string(b) != "xy" {
+		t.Errorf("call() with *[]byte = %q, %v, want %q, nil", b, err, "xy")
+	}
+}
+
+func TestConverterConvertLen(t *testing.T) {
+	// The export is looked up once per module, and a converter uses it only
+	// when the binary has it
+	tests := []struct {
+		name   string
+		binary []byte
+		want   string
+	}{
+		{"Without", stubWasm, "abc"},
+		{"With", sizedWasm, "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter("s2t.json", WithBinary(tt.binary))
+			if err != nil {
+				t.Fatalf("NewConverter() error = %v", err)
+			}
+			defer converter.Close()
+
+			if got, want := converter.mod.convertLen, tt.want == "ab"; got != want {
+				t.Errorf("convertLen = %v, want %v", got, want)
+			}
+			if got, err := converter.Convert("abc"); err != nil || got != tt.want {
+				t.Errorf("Convert() = %q, %v, want %q, nil", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallAllocFailure(t *testing.T) {
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	mod, err := r.Instantiate(ctx, allocFailWasm)
+	if err != nil {
+		t.Fatalf("Instantiate() error = %v", err)
+	}
+	m := &module{mod: mod}
+
+	// The argument doesn't fit, so opencc_convert must not be called with
+	// a null pointer
+	var result string
+	err = m.call(ctx, "opencc_convert", &result, uint32(1), strings.Repeat("字", 1<<15))
+	if !errors.Is(err, ErrOutOfMemory) {
+		t.Errorf("call() error = %v, want %v", err, ErrOutOfMemory)
+	}
+
+	// A closed module isn't out of memory
+	mod.Close(ctx)
+	err = m.call(ctx, "opencc_convert", &result, uint32(1), "字")
+	if err == nil || errors.Is(err, ErrOutOfMemory) {
+		t.Errorf("call() on closed module error = %v, want non-nil and not %v", err, ErrOutOfMemory)
+	}
+}
+
+// trapWasm is a module whose operator new traps like a failed allocation
+// does, next to a function that traps on its own.
+var trapWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: () -> (), (i32) -> i32
+	0x01, 0x09, 0x02,
+	0x60, 0x00, 0x00,
+	0x60, 0x01, 0x7f, 0x01, 0x7f,
+	// Functions: _Znwm, trap, new
+	0x03, 0x04, 0x03, 0x01, 0x00, 0x00,
+	// Exports: _Znwm, trap, new
+	0x07, 0x16, 0x03,
+	0x05, '_', 'Z', 'n', 'w', 'm', 0x00, 0x00,
+	0x04, 't', 'r', 'a', 'p', 0x00, 0x01,
+	0x03, 'n', 'e', 'w', 0x00, 0x02,
+	// Code
+	0x0a, 0x11, 0x03,
+	// _Znwm, trap: unreachable
+	0x03, 0x00, 0x00, 0x0b,
+	0x03, 0x00, 0x00, 0x0b,
+	// new: drop(_Znwm(8))
+	0x07, 0x00, 0x41, 0x08, 0x10, 0x00, 0x1a, 0x0b,
+}
+
+func TestAllocFailed(t *testing.T) {
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	mod, err := r.Instantiate(ctx, trapWasm)
+	if err != nil {
+		t.Fatalf("Instantiate() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"new", true},
+		{"trap", false},
+	}
+	for _, tt := range tests {
+		m := &module{mod: mod}
+		err := m.call(ctx, tt.name, nil)
+		if err == nil {
+			t.Fatalf("call(%q) error = nil, want a trap", tt.name)
+		}
+		if !m.trapped {
+			t.Errorf("call(%q) didn't mark the module as trapped", tt.name)
+		}
+		if got := m.allocFailed(err); got != tt.want {
+			t.Errorf("allocFailed() after calling %q = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package opencc
+
+import (
+	"crypto/sha256"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// DefaultMaxInputSize is the largest input, in bytes, a converter accepts
+// unless WithMaxInputSize says otherwise.
+const DefaultMaxInputSize = 256 << 20
+
+// Option configures a Converter created by NewConverter.
+type Option func(*options)
+
+type options struct {
+	engine *Engine // the converter is created in
+
+	stdout io.Writer
+	stderr io.Writer
+	dataFS fs.FS
+
+	binary    []byte
+	binaryKey [sha256.Size]byte
+
+	customDicts         []func() ([]byte, error)
+	maxInputSize        int
+	tracer              Tracer
+	moduleConfig        []func(wazero.ModuleConfig) wazero.ModuleConfig
+	skipValidation      bool
+	preserveWhitespace  bool
+	preservePunctuation bool
+	keepBOM             bool
+	normalizeNFC        bool
+	poolSize            int
+	poolIdleTTL         time.Duration
+	recycleAfter        int
+
+	// instantiateAttempts and instantiateBackoff configure retrying a
+	// failed module instantiation
+	instantiateAttempts int
+	instantiateBackoff  time.Duration
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		stdout: io.Discard,
+		stderr: io.Discard,
+
+		maxInputSize: DefaultMaxInputSize,
+
+		instantiateAttempts: 3,
+		instantiateBackoff:  10 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// root returns the filesystem mounted as the WASM module's root.
+func (o *options) root() (fs.FS, error) {
+	if o.dataFS != nil {
+		return o.dataFS, nil
+	}
+	return dataSubFS()
+}
+
+// WithStdout sets where the WASM module's standard output is written.
+// It is discarded by default.
+func WithStdout(w io.Writer) Option {
+	return func(o *options) {
+		o.stdout = w
+	}
+}
+
+// WithStderr sets where the WASM module's standard error is written.
+// It is discarded by default.
+func WithStderr(w io.Writer) Option {
+	return func(o *options) {
+		o.stderr = w
+	}
+}
+
+// WithDataFS mounts fsys as the WASM module's filesystem root instead of the
+// embedded dictionaries. Configuration files passed to NewConverter, and the
+// dictionaries they reference, are then resolved against the root of fsys.
+func WithDataFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.dataFS = fsys
+	}
+}
+
+// WithBinary instantiates converters from wasm, a custom build of
+// opencc.wasm, instead of the embedded binary. The binary is compiled once
+// per runtime and shared by every converter using the same bytes. Combine
+// it with WithDataFS to supply dictionaries matching the build.
+func WithBinary(wasm []byte) Option {
+	key := sha256.Sum256(wasm)
+	return func(o *options) {
+		o.binary = wasm
+		o.binaryKey = key
+	}
+}
+
+// WithSkipValidation skips checking that input is valid UTF-8 before
+// converting it. Callers that know their input is clean can use it to avoid
+// scanning the input on hot paths.
+func WithSkipValidation() Option {
+	return func(o *options) {
+		o.skipValidation = true
+	}
+}
+
+// WithCustomDict adds a dictionary read from r whose entries take
+// precedence over the configuration's built-in dictionaries. Each line
+// holds a term and its conversion separated by a tab, such as "苹果\t蘋果";
+// blank lines and lines starting with "#" are ignored. r is read once, when
+// the first converter using the option is created.
+//
+// Custom entries are added to the first step of the conversion chain and to
+// the segmentation, so multi-character terms are kept together. When a
+// built-in phrase is longer than any matching custom entry, the built-in
+// phrase still wins. Dictionaries from earlier options take precedence over
+// later ones.
+func WithCustomDict(r io.Reader) Option {
+	read := sync.OnceValues(func() ([]byte, error) {
+		return io.ReadAll(r)
+	})
+	return func(o *options) {
+		o.customDicts = append(o.customDicts, read)
+	}
+}
+
+// WithPreserveWhitespace guarantees that conversions leave whitespace and
+// line structure byte for byte as they were: spaces, tabs, line endings and
+// a missing final newline. OpenCC normally does so already; with this
+// option the whitespace of every result is checked against the input, and
+// if it differs the text between whitespace is converted piece by piece
+// instead.
+func WithPreserveWhitespace() Option {
+	return func(o *options) {
+		o.preserveWhitespace = true
+	}
+}
+
+// WithPreservePunctuation keeps punctuation, symbols and full-width and
+// half-width forms as they were, converting only the text between them.
+// None of the bundled configurations change punctuation or width, so this
+// matters for custom dictionaries or configurations that do, such as ones
+// mapping “” to 「」. Like WithPreserveWhitespace, results are checked
+// against the input and redone piece by piece if they differ.
+func WithPreservePunctuation() Option {
+	return func(o *options) {
+		o.preservePunctuation = true
+	}
+}
+
+// WithKeepBOM keeps a byte order mark at the start of the input at the
+// start of the result. By default it is stripped, so text exported by
+// editors that add one converts the same as text without. Only the start
+// of a document passed to Convert, ConvertBytes, ConvertTo or ConvertStream
+// is treated as a byte order mark; lines, fields and batch items converted
+// by the other methods and helpers keep a leading U+FEFF.
+func WithKeepBOM() Option {
+	return func(o *options) {
+		o.keepBOM = true
+	}
+}
+
+// WithNormalizeNFC normalizes input to Unicode Normalization Form C before
+// converting it, so text that is composed differently converts the same.
+// It also maps CJK compatibility ideographs, such as U+F91D, to the unified
+// ideographs the dictionaries contain. Off by default, as the result is then
+// no longer byte for byte the input where nothing was converted.
+func WithNormalizeNFC() Option {
+	return func(o *options) {
+		o.normalizeNFC = true
+	}
+}
+
+// WithPoolSize limits a ConverterPool to n converters, in use or idle, so
+// its memory stays bounded under load; Get waits for a converter to be
+// returned once n are in use. n <= 0 means no limit, which is the default.
+// Converters created on their own ignore it.
+func WithPoolSize(n int) Option {
+	return func(o *options) {
+		o.poolSize = n
+	}
+}
+
+// WithPoolIdleTimeout makes a ConverterPool close converters that have been
+// idle for longer than d, releasing their memory during quiet periods; they
+// are recreated when needed again, which costs as much as creating a new
+// converter. d <= 0 keeps idle converters until the pool is closed, which
+// is the default. The pool checks from a background goroutine that runs
+// until the pool is closed.
+func WithPoolIdleTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.poolIdleTTL = d
+	}
+}
+
+// WithRecycleAfter reinstantiates the converter's module after every n
+// successful conversions, releasing the WASM memory it has grown to. See
+// Converter.Recycle. n <= 0 never recycles, which is the default.
+func WithRecycleAfter(n int) Option {
+	return func(o *options) {
+		o.recycleAfter = n
+	}
+}
+
+// WithInstantiateRetry makes up to attempts tries at instantiating the
+// converter's module, waiting backoff after the first failure and doubling
+// the wait after each further one. Instantiation can fail transiently when
+// linear memory can't be allocated under memory pressure, such as when
+// creating many converters in a burst. Only a trapping start function is
+// retried; a link error, an exiting start function or a closed engine fails
+// at once. The default is 3 attempts with a backoff of 10ms; attempts <= 1
+// disables retrying.
+func WithInstantiateRetry(attempts int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.instantiateAttempts = attempts
+		o.instantiateBackoff = backoff
+	}
+}
+
+// WithMaxInputSize rejects inputs larger than n bytes with ErrInputTooLarge
+// before copying them into WASM memory, so a huge request body can't make
+// the converter's memory grow without bound. Streaming methods apply the
+// limit to each chunk. n <= 0 removes the limit. The default is
+// DefaultMaxInputSize (256 MiB).
+func WithMaxInputSize(n int) Option {
+	return func(o *options) {
+		o.maxInputSize = n
+	}
+}
+
+// WithTracer starts a span named "opencc.Convert" with t around each of
+// the converter's conversions, recording the configuration, input and
+// output lengths, and any error. The span is started from the context
+// passed to ConvertContext. Without a tracer no span is started.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+// WithWalltime sets the wall clock the WASM module reads, with the given
+// resolution. By default the module sees a fake clock that starts at a
+// fixed time and advances 1ms on each reading, so conversions never depend
+// on the host's time.
+func WithWalltime(walltime sys.Walltime, resolution sys.ClockResolution) Option {
+	return withModuleConfig(func(config wazero.ModuleConfig) wazero.ModuleConfig {
+		return config.WithWalltime(walltime, resolution)
+	})
+}
+
+// WithNanotime sets the monotonic clock the WASM module reads, with the
+// given resolution. Like the wall clock, it is fake by default.
+func WithNanotime(nanotime sys.Nanotime, resolution sys.ClockResolution) Option {
+	return withModuleConfig(func(config wazero.ModuleConfig) wazero.ModuleConfig {
+		return config.WithNanotime(nanotime, resolution)
+	})
+}
+
+// WithSysClock gives the WASM module the host's wall and monotonic clocks
+// instead of the fake ones.
+func WithSysClock() Option {
+	return withModuleConfig(func(config wazero.ModuleConfig) wazero.ModuleConfig {
+		return config.WithSysWalltime().WithSysNanotime()
+	})
+}
+
+// WithRandSource sets where the WASM module reads random bytes from. By
+// default it gets a deterministic source rather than host entropy. The
+// caller is responsible for closing r, if needed, after the converter is
+// closed.
+func WithRandSource(r io.Reader) Option {
+	return withModuleConfig(func(config wazero.ModuleConfig) wazero.ModuleConfig {
+		return config.WithRandSource(r)
+	})
+}
+
+func withModuleConfig(f func(wazero.ModuleConfig) wazero.ModuleConfig) Option {
+	return func(o *options) {
+		o.moduleConfig = append(o.moduleConfig, f)
+	}
+}
@@ -0,0 +1,177 @@
+package opencc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDefaultOutputDiscarded(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, w
+	defer func() {
+		os.Stdout, os.Stderr = stdout, stderr
+	}()
+
+	if converter, err := NewConverterFromFS(missingDictFS(t), "s2t.json"); err == nil {
+		converter.Close()
+	}
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	if _, err := converter.Convert("简体字"); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	converter.Close()
+
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 0 {
+		t.Errorf("output = %q, want none", out)
+	}
+}
+
+func TestWithDataFS(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for _, name := range []string{"STPhrases.ocd2", "STCharacters.ocd2"} {
+		data, err := readEmbedded(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+	config, err := readEmbedded("s2t.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys["custom.json"] = &fstest.MapFile{Data: config}
+
+	converter, err := NewConverter("custom.json", WithDataFS(fsys))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	result, err := converter.Convert("简体字")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result != "簡體字" {
+		t.Errorf("Convert() = %v, want %v", result, "簡體字")
+	}
+
+	if c, err := NewConverter("custom.json"); err == nil {
+		c.Close()
+		t.Error("NewConverter() without WithDataFS error = nil, want non-nil")
+	}
+}
+
+func TestWithBinary(t *testing.T) {
+	wasm := bytes.Clone(binary)
+	for i := 0; i < 2; i++ {
+		converter, err := NewConverter("s2t.json", WithBinary(wasm))
+		if err != nil {
+			t.Fatalf("NewConverter() error = %v", err)
+		}
+		result, err := converter.Convert("简体字")
+		converter.Close()
+		if err != nil || result != "簡體字" {
+			t.Fatalf("Convert() = %q, %v, want %q", result, err, "簡體字")
+		}
+	}
+
+	st, err := defaultEngine.runtime(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.mu.Lock()
+	_, cached := st.customCM[sha256.Sum256(wasm)]
+	st.mu.Unlock()
+	if !cached {
+		t.Error("custom binary was not cached")
+	}
+
+	if converter, err := NewConverter("s2t.json", WithBinary([]byte("not wasm"))); err == nil {
+		converter.Close()
+		t.Error("NewConverter() with an invalid binary error = nil, want non-nil")
+	}
+}
+
+func TestWithMaxInputSize(t *testing.T) {
+	converter, err := NewConverter("s2t.json", WithMaxInputSize(9))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	if got, err := converter.Convert("简体字"); err != nil || got != "簡體字" {
+		t.Errorf("Convert() at the limit = %q, %v, want %q", got, err, "簡體字")
+	}
+	if _, err := converter.Convert("简体字简体字"); !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("Convert() over the limit error = %v, want %v", err, ErrInputTooLarge)
+	}
+	if _, err := converter.ConvertBytes([]byte("简体字简体字")); !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("ConvertBytes() over the limit error = %v, want %v", err, ErrInputTooLarge)
+	}
+	if converter.IsClosed() {
+		t.Error("IsClosed() = true after rejecting a large input")
+	}
+
+	unlimited, err := NewConverter("s2t.json", WithMaxInputSize(0))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer unlimited.Close()
+
+	if _, err := unlimited.Convert("简体字简体字"); err != nil {
+		t.Errorf("Convert() without a limit error = %v", err)
+	}
+}
+
+func TestModuleConfigOptions(t *testing.T) {
+	walltime := func() (int64, int32) { return 1700000000, 0 }
+	nanotime := func() int64 { return 0 }
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"walltime", []Option{WithWalltime(walltime, 1)}},
+		{"nanotime", []Option{WithNanotime(nanotime, 1)}},
+		{"sys clock", []Option{WithSysClock()}},
+		{"rand source", []Option{WithRandSource(bytes.NewReader(make([]byte, 1024)))}},
+		{"all", []Option{WithWalltime(walltime, 1), WithNanotime(nanotime, 1), WithRandSource(rand.Reader)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(newOptions(tt.opts).moduleConfig); got != len(tt.opts) {
+				t.Errorf("options add %d module config functions, want %d", got, len(tt.opts))
+			}
+
+			converter, err := NewConverter("s2t.json", tt.opts...)
+			if err != nil {
+				t.Fatalf("NewConverter() error = %v", err)
+			}
+			defer converter.Close()
+
+			if result, err := converter.Convert("简体字"); err != nil || result != "簡體字" {
+				t.Errorf("Convert() = %q, %v, want %q, nil", result, err, "簡體字")
+			}
+		})
+	}
+}
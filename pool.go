@@ -0,0 +1,373 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConverterPool hands out Converters for a single configuration so that
+// concurrent callers never share a module instance. Converters are created
+// lazily and kept for reuse once returned with Put. With WithPoolSize, at
+// most that many converters exist at once and Get waits for one to be
+// returned when all are in use, which bounds the pool's memory. With
+// WithPoolIdleTimeout, converters left idle for longer are closed in the
+// background and recreated on demand.
+//
+// Idle converters are kept in a plain free list rather than a sync.Pool,
+// since a sync.Pool drops items during garbage collection without giving
+// the pool a chance to close their WASM modules.
+type ConverterPool struct {
+	configFile string
+	opts       []Option
+	size       int           // maximum number of converters, or 0 for no limit
+	idleTTL    time.Duration // after which idle converters are closed, or 0
+	stop       chan struct{} // closed to stop the sweeper
+	swept      chan struct{} // closed when the sweeper has stopped
+
+	mu       sync.Mutex
+	idle     []idleConverter // least recently returned first
+	inUse    int             // handed out by Get, or being created for it
+	waiters  []chan struct{} // Get calls waiting for a converter, oldest first
+	closed   bool
+	returned chan struct{} // closed when the last converter in use is returned after Close
+}
+
+// idleConverter is an idle converter of a ConverterPool.
+type idleConverter struct {
+	c     *Converter
+	since time.Time // returned to the pool
+}
+
+// PoolStats describes the converters of a ConverterPool at one moment.
+type PoolStats struct {
+	InUse   int // handed out by Get and not yet returned with Put
+	Idle    int // ready for reuse
+	Waiters int // Get calls waiting for a converter to be returned
+}
+
+// NewConverterPool creates a pool of converters for configFile. The options
+// are applied to every converter the pool creates.
+func NewConverterPool(configFile string, opts ...Option) *ConverterPool {
+	o := newOptions(opts)
+	p := &ConverterPool{
+		configFile: configFile,
+		opts:       opts,
+		size:       o.poolSize,
+		idleTTL:    o.poolIdleTTL,
+	}
+	if p.idleTTL > 0 {
+		p.stop = make(chan struct{})
+		p.swept = make(chan struct{})
+		go p.sweep()
+	}
+	return p
+}
+
+// sweep closes converters that have been idle for longer than p.idleTTL,
+// checking at a fraction of it, until p.stop is closed.
+func (p *ConverterPool) sweep() {
+	defer close(p.swept)
+
+	ticker := time.NewTicker(max(p.idleTTL/4, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			p.closeIdle(now.Add(-p.idleTTL))
+		}
+	}
+}
+
+// closeIdle closes the idle converters returned before cutoff.
+func (p *ConverterPool) closeIdle(cutoff time.Time) {
+	p.mu.Lock()
+	n := 0
+	for n < len(p.idle) && p.idle[n].since.Before(cutoff) {
+		n++
+	}
+	expired := slices.Clone(p.idle[:n])
+	p.idle = slices.Delete(p.idle, 0, n)
+	p.mu.Unlock()
+
+	for _, ic := range expired {
+		if err := ic.c.Close(); err != nil {
+			getLogger().Warn("error closing idle converter", "config", p.configFile, "error", err)
+		}
+	}
+}
+
+// Get returns an idle converter, creating a new one if none is available.
+// The converter must be handed back with Put when no longer needed. If the
+// pool is at its size limit, Get waits until a converter is returned.
+func (p *ConverterPool) Get() (*Converter, error) {
+	return p.GetContext(context.Background())
+}
+
+// GetContext is like Get, but stops waiting for a converter to be returned
+// when ctx is done, returning ctx.Err(). Creating a new converter is
+// bounded by ctx as in NewConverterContext.
+func (p *ConverterPool) GetContext(ctx context.Context) (*Converter, error) {
+	p.mu.Lock()
+	for {
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrInvalidConverter
+		}
+		if n := len(p.idle); n > 0 {
+			c := p.idle[n-1].c
+			p.idle = p.idle[:n-1]
+			p.inUse++
+			p.mu.Unlock()
+			if !c.IsClosed() {
+				return c, nil
+			}
+
+			// Its module was closed by Shutdown or Engine.Close
+			c.Close()
+			p.mu.Lock()
+			p.release()
+			continue
+		}
+		if p.size <= 0 || p.inUse < p.size {
+			break
+		}
+
+		wake := make(chan struct{}, 1)
+		p.waiters = append(p.waiters, wake)
+		p.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			p.mu.Lock()
+			if i := slices.Index(p.waiters, wake); i >= 0 {
+				p.waiters = slices.Delete(p.waiters, i, i+1)
+			} else {
+				// Pass on the wake-up this call won't use
+				p.wake()
+			}
+			p.mu.Unlock()
+			return nil, ctx.Err()
+		}
+		p.mu.Lock()
+	}
+
+	// Reserve the converter's place before creating it without the lock
+	p.inUse++
+	p.mu.Unlock()
+
+	c, err := NewConverterContext(ctx, p.configFile, p.opts...)
+	if err != nil {
+		p.mu.Lock()
+		p.release()
+		p.mu.Unlock()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Put returns a converter obtained from Get to the pool. Converters returned
+// after the pool is closed, or that can no longer be used, are closed
+// immediately.
+func (p *ConverterPool) Put(c *Converter) {
+	if c == nil {
+		return
+	}
+	broken := c.IsClosed()
+
+	p.mu.Lock()
+	if broken || p.closed {
+		p.release()
+		p.mu.Unlock()
+		c.Close() // also releases an interrupted converter's module
+		return
+	}
+	p.inUse = max(p.inUse-1, 0)
+	p.idle = append(p.idle, idleConverter{c: c, since: time.Now()})
+	p.wake()
+	p.mu.Unlock()
+}
+
+// release gives up the place of a converter in use that is closed or
+// failed to be created. p.mu must be held.
+func (p *ConverterPool) release() {
+	p.inUse = max(p.inUse-1, 0)
+	if p.returned != nil && p.inUse == 0 {
+		close(p.returned)
+		p.returned = nil
+	}
+	p.wake()
+}
+
+// wake wakes the Get call that has waited longest, if any. p.mu must be
+// held.
+func (p *ConverterPool) wake() {
+	if len(p.waiters) > 0 {
+		p.waiters[0] <- struct{}{}
+		p.waiters = p.waiters[1:]
+	}
+}
+
+// Stats reports how many of the pool's converters are in use and idle, and
+// how many Get calls are waiting for one.
+func (p *ConverterPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PoolStats{InUse: p.inUse, Idle: len(p.idle), Waiters: len(p.waiters)}
+}
+
+// Convert converts the input text using a converter from the pool.
+func (p *ConverterPool) Convert(input string) (string, error) {
+	c, err := p.Get()
+	if err != nil {
+		return "", err
+	}
+	defer p.Put(c)
+
+	return c.Convert(input)
+}
+
+// ConvertParallel converts inputs using up to workers converters from the
+// pool at once and returns the results in input order. If workers is not
+// positive, GOMAXPROCS is used. On the first failure no further inputs are
+// started, and the error reports the index of the input that failed.
+func (p *ConverterPool) ConvertParallel(inputs []string, workers int) ([]string, error) {
+	return p.convertParallel(inputs, workers, func(i int, err error) error {
+		return fmt.Errorf("convert input %d: %w", i, err)
+	})
+}
+
+// convertParallel implements ConvertParallel, wrapping the error converting
+// inputs[i] with wrap.
+func (p *ConverterPool) convertParallel(inputs []string, workers int, wrap func(i int, err error) error) ([]string, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(inputs))
+
+	results := make([]string, len(inputs))
+	var (
+		next     atomic.Int64
+		failed   atomic.Bool
+		errOnce  sync.Once
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		errOnce.Do(func() { firstErr = err })
+		failed.Store(true)
+	}
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			c, err := p.Get()
+			if err != nil {
+				fail(err)
+				return
+			}
+			defer p.Put(c)
+
+			for !failed.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= len(inputs) {
+					return
+				}
+				if inputs[i] == "" {
+					continue
+				}
+
+				result, err := c.convertPart(inputs[i])
+				if err != nil {
+					fail(wrap(i, err))
+					return
+				}
+				results[i] = result
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+// Close closes all idle converters, then waits until every converter in use
+// has been returned with Put and closed. Waiting Get calls return
+// ErrInvalidConverter as soon as Close is called. With WithPoolIdleTimeout,
+// Close also waits for the background sweep to stop, so no converter is
+// closed after it returns. Use Shutdown to bound the wait.
+func (p *ConverterPool) Close() error {
+	return p.Shutdown(context.Background())
+}
+
+// Shutdown closes the pool like Close, but gives up waiting for converters in
+// use when ctx is done, returning ctx.Err(). Converters returned after that
+// are still closed by Put.
+func (p *ConverterPool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.close()
+	returned := p.returned
+	if returned == nil && p.inUse > 0 {
+		returned = make(chan struct{})
+		p.returned = returned
+	}
+	p.mu.Unlock()
+
+	err := p.drain()
+	p.waitSwept()
+	if returned != nil {
+		select {
+		case <-returned:
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		}
+	}
+	return err
+}
+
+// close marks the pool closed, stops the sweeper and wakes every waiting
+// Get call. p.mu must be held.
+func (p *ConverterPool) close() {
+	if p.stop != nil && !p.closed {
+		close(p.stop)
+	}
+	p.closed = true
+	for len(p.waiters) > 0 {
+		p.wake()
+	}
+}
+
+// waitSwept waits for the sweeper to stop after close, if there is one.
+func (p *ConverterPool) waitSwept() {
+	if p.swept != nil {
+		<-p.swept
+	}
+}
+
+// drain closes all idle converters, leaving the pool usable.
+func (p *ConverterPool) drain() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	var errs []error
+	for _, ic := range idle {
+		errs = append(errs, ic.c.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,401 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConverterPool(t *testing.T) {
+	pool := NewConverterPool("s2t.json")
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				result, err := pool.Convert("这是一个测试")
+				if err != nil {
+					errs <- err
+					return
+				}
+				if result != "這是一個測試" {
+					errs <- errors.New("unexpected result: " + result)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestConverterPoolClose(t *testing.T) {
+	pool := NewConverterPool("s2t.json")
+
+	c, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	closed := make(chan error)
+	go func() { closed <- pool.Close() }()
+
+	select {
+	case err := <-closed:
+		t.Fatalf("Close() returned %v with a converter in use", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	pool.Put(c)
+	if err := <-closed; err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !c.IsClosed() {
+		t.Error("Close() returned before the converter in use was closed")
+	}
+
+	if _, err := pool.Get(); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("Get() after Close() error = %v, want %v", err, ErrInvalidConverter)
+	}
+}
+
+func TestConvertParallel(t *testing.T) {
+	pool := NewConverterPool("s2t.json")
+	defer pool.Close()
+
+	inputs := make([]string, 200)
+	for i := range inputs {
+		if i%10 != 0 {
+			inputs[i] = fmt.Sprintf("第%d个简体字", i)
+		}
+	}
+
+	for _, workers := range []int{0, 1, 4, 1000} {
+		t.Run(fmt.Sprint(workers), func(t *testing.T) {
+			results, err := pool.ConvertParallel(inputs, workers)
+			if err != nil {
+				t.Fatalf("ConvertParallel() error = %v", err)
+			}
+			if len(results) != len(inputs) {
+				t.Fatalf("len(results) = %d, want %d", len(results), len(inputs))
+			}
+			for i, result := range results {
+				want := ""
+				if inputs[i] != "" {
+					want = fmt.Sprintf("第%d個簡體字", i)
+				}
+				if result != want {
+					t.Errorf("results[%d] = %q, want %q", i, result, want)
+				}
+			}
+		})
+	}
+
+	results, err := pool.ConvertParallel(nil, 4)
+	if err != nil || len(results) != 0 {
+		t.Errorf("ConvertParallel(nil) = %q, %v, want empty result", results, err)
+	}
+}
+
+func TestConvertParallelError(t *testing.T) {
+	pool := NewConverterPool("s2t.json")
+	defer pool.Close()
+
+	inputs := []string{"简体", "简体", "简\xff体", "简体"}
+	_, err := pool.ConvertParallel(inputs, 2)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("ConvertParallel() error = %v, want %v", err, ErrInvalidInput)
+	}
+	if !strings.Contains(err.Error(), "input 2") {
+		t.Errorf("ConvertParallel() error = %v, want index 2", err)
+	}
+}
+
+func BenchmarkConverterPool(b *testing.B) {
+	pool := NewConverterPool("s2t.json")
+	defer pool.Close()
+
+	input := "这是一个很长的测试文本，用来测试转换性能。包含了很多常用的汉字。"
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := pool.Convert(input); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkConvertParallel(b *testing.B) {
+	inputs := make([]string, 1000)
+	for i := range inputs {
+		inputs[i] = "这是一个很长的测试文本，用来测试转换性能。"
+	}
+
+	b.Run("serial", func(b *testing.B) {
+		converter, err := NewConverter("s2t.json")
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer converter.Close()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := converter.ConvertBatch(inputs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		pool := NewConverterPool("s2t.json")
+		defer pool.Close()
+
+		// Warm up the pool so module creation isn't measured.
+		if _, err := pool.ConvertParallel(inputs, 0); err != nil {
+			b.Fatal(err)
+		}
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := pool.ConvertParallel(inputs, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestConverterPoolSize(t *testing.T) {
+	pool := NewConverterPool("s2t.json", WithPoolSize(2))
+	defer pool.Close()
+
+	a, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	b, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got, want := pool.Stats(), (PoolStats{InUse: 2}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.GetContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetContext() at the limit error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	got := make(chan *Converter)
+	go func() {
+		c, err := pool.Get()
+		if err != nil {
+			t.Error(err)
+		}
+		got <- c
+	}()
+	for pool.Stats().Waiters == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	pool.Put(a)
+	if c := <-got; c != a {
+		t.Error("Get() didn't return the converter handed back with Put")
+	}
+	pool.Put(a)
+	pool.Put(b)
+	if got, want := pool.Stats(), (PoolStats{Idle: 2}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConverterPoolSizeClose(t *testing.T) {
+	pool := NewConverterPool("s2t.json", WithPoolSize(1))
+
+	c, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	errs := make(chan error)
+	go func() {
+		_, err := pool.Get()
+		errs <- err
+	}()
+	for pool.Stats().Waiters == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	closed := make(chan error)
+	go func() { closed <- pool.Close() }()
+	if err := <-errs; !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("waiting Get() after Close() error = %v, want %v", err, ErrInvalidConverter)
+	}
+	pool.Put(c)
+	if err := <-closed; err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestConverterPoolShutdown(t *testing.T) {
+	pool := NewConverterPool("s2t.json")
+
+	c, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() with a converter in use error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		pool.Put(c)
+	}()
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if !c.IsClosed() {
+		t.Error("Shutdown() returned before the converter in use was closed")
+	}
+}
+
+func TestConverterPoolAfterShutdown(t *testing.T) {
+	pool := NewConverterPool("s2t.json", WithPoolSize(2))
+	defer pool.Close()
+
+	idle, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	inUse, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	pool.Put(idle)
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if !inUse.IsClosed() {
+		t.Error("IsClosed() after Shutdown() = false, want true")
+	}
+	if _, err := inUse.Convert("简体字"); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("Convert() after Shutdown() error = %v, want %v", err, ErrInvalidConverter)
+	}
+
+	// Neither the idle converter nor the one returned is handed out again
+	pool.Put(inUse)
+	for i := 0; i < 2; i++ {
+		result, err := pool.Convert("简体字")
+		if err != nil {
+			t.Fatalf("Convert() after Shutdown() error = %v", err)
+		}
+		if result != "簡體字" {
+			t.Errorf("Convert() after Shutdown() = %v, want %v", result, "簡體字")
+		}
+	}
+	if got, want := pool.Stats(), (PoolStats{Idle: 1}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConvertParallelPoolSize(t *testing.T) {
+	pool := NewConverterPool("s2t.json", WithPoolSize(1))
+	defer pool.Close()
+
+	inputs := []string{"简体字", "汉字", "", "这是一个测试"}
+	results, err := pool.ConvertParallel(inputs, 4)
+	if err != nil {
+		t.Fatalf("ConvertParallel() error = %v", err)
+	}
+	if want := []string{"簡體字", "漢字", "", "這是一個測試"}; fmt.Sprint(results) != fmt.Sprint(want) {
+		t.Errorf("ConvertParallel() = %q, want %q", results, want)
+	}
+	if got, want := pool.Stats(), (PoolStats{Idle: 1}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConverterPoolIdleTimeout(t *testing.T) {
+	pool := NewConverterPool("s2t.json", WithPoolIdleTimeout(20*time.Millisecond))
+
+	old, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	recent, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	pool.Put(old) // recent stays in use, so it is never swept
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !old.IsClosed() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !old.IsClosed() {
+		t.Fatal("idle converter wasn't closed after the idle timeout")
+	}
+	if recent.IsClosed() {
+		t.Error("converter in use was closed")
+	}
+	if got, want := pool.Stats(), (PoolStats{InUse: 1}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	pool.Put(recent)
+
+	// A new converter is created on demand
+	if got, err := pool.Convert("简体字"); err != nil || got != "簡體字" {
+		t.Errorf("Convert() = %q, %v, want %q", got, err, "簡體字")
+	}
+
+	pool.Close()
+	select {
+	case <-pool.swept:
+	default:
+		t.Error("Close() returned before the sweeper stopped")
+	}
+	pool.Close() // closing again doesn't stop the sweeper twice
+}
+
+func TestConverterPoolCloseIdle(t *testing.T) {
+	pool := NewConverterPool("s2t.json")
+	defer pool.Close()
+
+	a, _ := pool.Get()
+	b, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	pool.Put(a)
+	cutoff := time.Now().Add(time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	pool.Put(b)
+
+	pool.closeIdle(cutoff)
+	if !a.IsClosed() || b.IsClosed() {
+		t.Errorf("closeIdle() closed a: %v, b: %v, want only a", a.IsClosed(), b.IsClosed())
+	}
+	if got := pool.Stats().Idle; got != 1 {
+		t.Errorf("Stats().Idle = %d, want 1", got)
+	}
+}
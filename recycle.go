@@ -0,0 +1,55 @@
+package opencc
+
+import (
+	"context"
+	"fmt"
+)
+
+// Recycle replaces c's module instance with a fresh one for the same
+// configuration. WASM linear memory never shrinks, so a long-lived
+// converter holds on to as much memory as its largest conversion needed,
+// plus anything OpenCC or the C runtime leaked; recycling releases it.
+// Most programs don't need this. Consider it when MemoryStats keeps
+// growing, or after converting an unusually large input.
+//
+// Recycle also revives a converter interrupted by a done context. If the
+// new instance can't be created, c keeps its current one and the error is
+// returned. Recycling a closed converter returns ErrInvalidConverter.
+func (c *Converter) Recycle() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.mod == nil {
+		return ErrInvalidConverter
+	}
+	return c.recycle(context.Background())
+}
+
+// recycle swaps in a new module instance. c.mu must be held.
+func (c *Converter) recycle(ctx context.Context) error {
+	mod, handle, err := openModule(ctx, c.options, c.config)
+	if err != nil {
+		return fmt.Errorf("recycle: %w", err)
+	}
+
+	if err := c.closeModule(); err != nil {
+		getLogger().Warn("error closing recycled module", "config", c.config, "error", err)
+	}
+	c.mod, c.handle, c.conversions = mod, handle, 0
+	return nil
+}
+
+// countConversion counts a successful conversion and recycles the module
+// once WithRecycleAfter's limit is reached. c.mu must be held.
+func (c *Converter) countConversion(ctx context.Context) {
+	c.conversions++
+	if c.recycleAfter <= 0 || c.conversions < c.recycleAfter {
+		return
+	}
+
+	// The conversion already succeeded, so only log a failure; the next
+	// conversion tries again.
+	if err := c.recycle(ctx); err != nil {
+		getLogger().Warn("error recycling converter", "config", c.config, "error", err)
+	}
+}
@@ -0,0 +1,100 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConverterRecycle(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	fresh, err := converter.MemoryStats()
+	if err != nil {
+		t.Fatalf("MemoryStats() error = %v", err)
+	}
+
+	if _, err := converter.Convert(strings.Repeat("简体字", 1<<16)); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if err := converter.Recycle(); err != nil {
+		t.Fatalf("Recycle() error = %v", err)
+	}
+
+	stats, err := converter.MemoryStats()
+	if err != nil {
+		t.Fatalf("MemoryStats() error = %v", err)
+	}
+	if stats.Size != fresh.Size {
+		t.Errorf("MemoryStats().Size after Recycle() = %d, want %d", stats.Size, fresh.Size)
+	}
+
+	if got, err := converter.Convert("简体字"); err != nil || got != "簡體字" {
+		t.Errorf("Convert() after Recycle() = %q, %v, want %q", got, err, "簡體字")
+	}
+}
+
+func TestConverterRecycleInterrupted(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := converter.ConvertContext(ctx, "简体字"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ConvertContext() error = %v, want context.Canceled", err)
+	}
+	if err := converter.Recycle(); err != nil {
+		t.Fatalf("Recycle() error = %v", err)
+	}
+	if got, err := converter.Convert("简体字"); err != nil || got != "簡體字" {
+		t.Errorf("Convert() after Recycle() = %q, %v, want %q", got, err, "簡體字")
+	}
+
+	converter.Close()
+	if err := converter.Recycle(); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("Recycle() after Close error = %v, want ErrInvalidConverter", err)
+	}
+}
+
+func TestWithRecycleAfter(t *testing.T) {
+	converter, err := NewConverter("s2t.json", WithRecycleAfter(5))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	fresh, err := converter.MemoryStats()
+	if err != nil {
+		t.Fatalf("MemoryStats() error = %v", err)
+	}
+
+	// Each round grows memory with a large input, then converts small ones
+	// until the converter is recycled
+	large := strings.Repeat("简体字", 1<<16)
+	for round := 0; round < 2; round++ {
+		if _, err := converter.Convert(large); err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		for i := 0; i < 4; i++ {
+			if got, err := converter.Convert("简体字"); err != nil || got != "簡體字" {
+				t.Fatalf("Convert() = %q, %v, want %q", got, err, "簡體字")
+			}
+		}
+
+		stats, err := converter.MemoryStats()
+		if err != nil {
+			t.Fatalf("MemoryStats() error = %v", err)
+		}
+		if stats.Size != fresh.Size {
+			t.Errorf("round %d: MemoryStats().Size = %d, want %d", round, stats.Size, fresh.Size)
+		}
+	}
+}
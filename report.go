@@ -0,0 +1,241 @@
+package opencc
+
+import (
+	"slices"
+	"unicode"
+)
+
+// Report describes how a conversion changed its input.
+type Report struct {
+	Output  string // converted text
+	Changed int    // number of input runes that were converted
+	Offsets []int  // rune offsets in the input of the converted runes
+}
+
+// OffsetPair maps the input runes [InStart, InEnd) to the output runes
+// [OutStart, OutEnd) they were converted to.
+type OffsetPair struct {
+	InStart, InEnd   int
+	OutStart, OutEnd int
+}
+
+// reportLookahead bounds how far alignRunes looks ahead to realign input
+// and output after a conversion changed the number of runes.
+const reportLookahead = 16
+
+// ConvertReport converts input and reports which of its runes the
+// conversion changed. A Report with Changed == 0 means the output is the
+// same as the input, apart from a byte order mark Convert leaves out. With
+// WithNormalizeNFC, the runes are compared and counted after normalizing
+// the input.
+func (c *Converter) ConvertReport(input string) (Report, error) {
+	if c.IsClosed() {
+		return Report{}, ErrInvalidConverter
+	}
+	if input == "" {
+		return Report{}, nil
+	}
+
+	output, in, shift, err := c.convertForDiff(input)
+	if err != nil {
+		return Report{}, err
+	}
+
+	offsets := diffRunes(in, []rune(output))
+	for i := range offsets {
+		offsets[i] += shift
+	}
+	return Report{Output: output, Changed: len(offsets), Offsets: offsets}, nil
+}
+
+// ConvertWithMapping converts input and maps rune ranges of the input to
+// the rune ranges of the output they were converted to. The pairs are in
+// order and cover both input and output completely: runs of unchanged runes
+// form one pair each, as does each converted rune or, where the conversion
+// changed the number of runes, each converted span. A byte order mark
+// Convert leaves out maps to an empty range at the start of the output.
+// With WithNormalizeNFC, input ranges count runes of the normalized input.
+func (c *Converter) ConvertWithMapping(input string) (output string, mapping []OffsetPair, err error) {
+	if c.IsClosed() {
+		return "", nil, ErrInvalidConverter
+	}
+	if input == "" {
+		return "", nil, nil
+	}
+
+	output, in, shift, err := c.convertForDiff(input)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pairs := alignRunes(in, []rune(output))
+	if shift == 0 {
+		return output, pairs, nil
+	}
+	mapping = append(make([]OffsetPair, 0, len(pairs)+1), OffsetPair{0, shift, 0, 0})
+	for _, p := range pairs {
+		p.InStart += shift
+		p.InEnd += shift
+		mapping = append(mapping, p)
+	}
+	return output, mapping, nil
+}
+
+// convertForDiff converts input like Convert and returns the runes of the
+// text OpenCC converted, to compare with output. A byte order mark kept
+// with WithKeepBOM is included in both; one left out of output is left
+// out of in as well, and shift is the number of runes in input before in.
+func (c *Converter) convertForDiff(input string) (output string, in []rune, shift int, err error) {
+	output, err = c.Convert(input)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	text, hasBOM := c.prepare(input, true)
+	prepared := text.(string)
+	switch {
+	case hasBOM && c.keepBOM:
+		prepared = bom + prepared
+	case hasBOM:
+		shift = 1
+	}
+	return output, []rune(prepared), shift, nil
+}
+
+// CoverageStats summarizes how much of a text's Chinese a conversion
+// changed.
+type CoverageStats struct {
+	Han        int // Chinese characters in the input
+	HanChanged int // of those, how many the conversion changed
+	Other      int // other runes in the input: Latin, digits, punctuation...
+	// OtherChanged counts other runes that changed, such as punctuation
+	// some configurations convert
+	OtherChanged int
+}
+
+// Percent returns the percentage of Chinese characters that changed, or 0
+// if the input has none.
+func (s CoverageStats) Percent() float64 {
+	if s.Han == 0 {
+		return 0
+	}
+	return 100 * float64(s.HanChanged) / float64(s.Han)
+}
+
+// Coverage compares input with output, its conversion, and counts the
+// Chinese characters and other runes that changed. A conversion run with
+// the wrong configuration typically shows up as a very low Percent.
+func Coverage(input, output string) CoverageStats {
+	in := []rune(input)
+
+	var stats CoverageStats
+	for _, r := range in {
+		if unicode.Is(unicode.Han, r) {
+			stats.Han++
+		} else {
+			stats.Other++
+		}
+	}
+	for _, i := range diffRunes(in, []rune(output)) {
+		if unicode.Is(unicode.Han, in[i]) {
+			stats.HanChanged++
+		} else {
+			stats.OtherChanged++
+		}
+	}
+	return stats
+}
+
+// diffRunes returns the offsets of the runes in in that don't appear
+// unchanged in out.
+func diffRunes(in, out []rune) []int {
+	var offsets []int
+	for _, p := range alignRunes(in, out) {
+		if slices.Equal(in[p.InStart:p.InEnd], out[p.OutStart:p.OutEnd]) {
+			continue
+		}
+		for i := p.InStart; i < p.InEnd; i++ {
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
+
+// alignRunes maps the runes of in to those of out. Most conversions
+// replace runes one for one, so in and out are compared rune by rune when
+// they have the same length. Otherwise, after a mismatch they are realigned
+// at the nearest pair of positions where two consecutive runes match again.
+func alignRunes(in, out []rune) []OffsetPair {
+	var pairs []OffsetPair
+	same := -1 // start of the current run of unchanged runes in in
+	i, j := 0, 0
+	endRun := func() {
+		if same >= 0 {
+			n := i - same
+			pairs = append(pairs, OffsetPair{same, i, j - n, j})
+			same = -1
+		}
+	}
+
+	for i < len(in) && j < len(out) {
+		if in[i] == out[j] {
+			if same < 0 {
+				same = i
+			}
+			i++
+			j++
+			continue
+		}
+		endRun()
+
+		di, dj := 1, 1
+		if len(in) != len(out) {
+			var ok bool
+			if di, dj, ok = realign(in[i:], out[j:]); !ok {
+				break
+			}
+		}
+		pairs = append(pairs, OffsetPair{i, i + di, j, j + dj})
+		i += di
+		j += dj
+	}
+	endRun()
+
+	if i < len(in) || j < len(out) {
+		pairs = append(pairs, OffsetPair{i, len(in), j, len(out)})
+	}
+	return pairs
+}
+
+// realign finds the smallest skips into in and out after which they match
+// again, preferring the smallest total skip. The end of both slices counts
+// as a match.
+func realign(in, out []rune) (di, dj int, ok bool) {
+	for total := 1; total <= 2*reportLookahead; total++ {
+		for di = min(total, reportLookahead); di >= 0 && total-di <= reportLookahead; di-- {
+			dj = total - di
+			if di > len(in) || dj > len(out) {
+				continue
+			}
+			if matchAt(in[di:], out[dj:]) {
+				return di, dj, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// matchAt reports whether in and out start with the same two runes, or end
+// together.
+func matchAt(in, out []rune) bool {
+	n := min(2, len(in), len(out))
+	if n < 2 && len(in) != len(out) {
+		return false
+	}
+	for k := 0; k < n; k++ {
+		if in[k] != out[k] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,206 @@
+package opencc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestConvertReport(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		name    string
+		input   string
+		output  string
+		offsets []int
+	}{
+		{"Changed", "简体中文", "簡體中文", []int{0, 1}},
+		{"Unchanged", "中文", "中文", nil},
+		{"Empty", "", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := converter.ConvertReport(tt.input)
+			if err != nil {
+				t.Fatalf("ConvertReport() error = %v", err)
+			}
+			if report.Output != tt.output {
+				t.Errorf("Output = %q, want %q", report.Output, tt.output)
+			}
+			if !slices.Equal(report.Offsets, tt.offsets) {
+				t.Errorf("Offsets = %v, want %v", report.Offsets, tt.offsets)
+			}
+			if report.Changed != len(tt.offsets) {
+				t.Errorf("Changed = %d, want %d", report.Changed, len(tt.offsets))
+			}
+		})
+	}
+}
+
+func TestConvertReportPrepared(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		input   string
+		output  string
+		offsets []int
+	}{
+		// Offsets still count the byte order mark Convert leaves out
+		{"BOM", nil, "\ufeff简体ab", "簡體ab", []int{1, 2}},
+		{"KeepBOM", []Option{WithKeepBOM()}, "\ufeff简体ab", "\ufeff簡體ab", []int{1, 2}},
+		// The decomposed é is one rune once normalized
+		{"NFC", []Option{WithNormalizeNFC()}, "e\u0301简体", "é簡體", []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter("s2t.json", tt.opts...)
+			if err != nil {
+				t.Fatalf("NewConverter() error = %v", err)
+			}
+			defer converter.Close()
+
+			report, err := converter.ConvertReport(tt.input)
+			if err != nil {
+				t.Fatalf("ConvertReport() error = %v", err)
+			}
+			if report.Output != tt.output || report.Changed != len(tt.offsets) || !slices.Equal(report.Offsets, tt.offsets) {
+				t.Errorf("ConvertReport() = %+v, want Output %q, Offsets %v", report, tt.output, tt.offsets)
+			}
+		})
+	}
+}
+
+func TestDiffRunes(t *testing.T) {
+	tests := []struct {
+		name    string
+		in, out string
+		want    []int
+	}{
+		{"Same", "中文字", "中文字", nil},
+		{"Replaced", "简体中文", "簡體中文", []int{0, 1}},
+		{"Longer", "插入U盘即可", "插入隨身碟即可", []int{2, 3}},
+		{"Shorter", "插入隨身碟即可", "插入U盘即可", []int{2, 3, 4}},
+		{"AtEnd", "使用U盘", "使用隨身碟", []int{2, 3}},
+		{"AtStart", "U盘好用", "隨身碟好用", []int{0, 1}},
+		{"Removed", "中文字", "中字", []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffRunes([]rune(tt.in), []rune(tt.out))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("diffRunes(%q, %q) = %v, want %v", tt.in, tt.out, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertWithMapping(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	output, mapping, err := converter.ConvertWithMapping("这是简体中文")
+	if err != nil {
+		t.Fatalf("ConvertWithMapping() error = %v", err)
+	}
+	if output != "這是簡體中文" {
+		t.Errorf("ConvertWithMapping() output = %q, want %q", output, "這是簡體中文")
+	}
+	want := []OffsetPair{{0, 1, 0, 1}, {1, 2, 1, 2}, {2, 3, 2, 3}, {3, 4, 3, 4}, {4, 6, 4, 6}}
+	if !slices.Equal(mapping, want) {
+		t.Errorf("ConvertWithMapping() mapping = %v, want %v", mapping, want)
+	}
+}
+
+func TestConvertWithMappingPrepared(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		input string
+		want  []OffsetPair
+	}{
+		{"BOM", nil, "\ufeff简ab", []OffsetPair{{0, 1, 0, 0}, {1, 2, 0, 1}, {2, 4, 1, 3}}},
+		{"KeepBOM", []Option{WithKeepBOM()}, "\ufeff简ab", []OffsetPair{{0, 1, 0, 1}, {1, 2, 1, 2}, {2, 4, 2, 4}}},
+		{"NFC", []Option{WithNormalizeNFC()}, "e\u0301简", []OffsetPair{{0, 1, 0, 1}, {1, 2, 1, 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter("s2t.json", tt.opts...)
+			if err != nil {
+				t.Fatalf("NewConverter() error = %v", err)
+			}
+			defer converter.Close()
+
+			_, mapping, err := converter.ConvertWithMapping(tt.input)
+			if err != nil {
+				t.Fatalf("ConvertWithMapping() error = %v", err)
+			}
+			if !slices.Equal(mapping, tt.want) {
+				t.Errorf("ConvertWithMapping() mapping = %v, want %v", mapping, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlignRunes(t *testing.T) {
+	tests := []struct {
+		name    string
+		in, out string
+		want    []OffsetPair
+	}{
+		{"Same", "中文字", "中文字", []OffsetPair{{0, 3, 0, 3}}},
+		{"Replaced", "简体中文", "簡體中文", []OffsetPair{{0, 1, 0, 1}, {1, 2, 1, 2}, {2, 4, 2, 4}}},
+		{"Longer", "插入U盘即可", "插入隨身碟即可", []OffsetPair{{0, 2, 0, 2}, {2, 4, 2, 5}, {4, 6, 5, 7}}},
+		{"AtEnd", "使用U盘", "使用隨身碟", []OffsetPair{{0, 2, 0, 2}, {2, 4, 2, 5}}},
+		{"Inserted", "中字", "中文字", []OffsetPair{{0, 1, 0, 1}, {1, 1, 1, 2}, {1, 2, 2, 3}}},
+		{"Empty", "", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := alignRunes([]rune(tt.in), []rune(tt.out))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("alignRunes(%q, %q) = %v, want %v", tt.in, tt.out, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		output  string
+		want    CoverageStats
+		percent float64
+	}{
+		{"all changed", "简体", "簡體", CoverageStats{Han: 2, HanChanged: 2}, 100},
+		{"mixed", "简体中文 OK!", "簡體中文 OK!", CoverageStats{Han: 4, HanChanged: 2, Other: 4}, 50},
+		{"punctuation", "“简”", "「簡」", CoverageStats{Han: 1, HanChanged: 1, Other: 2, OtherChanged: 2}, 100},
+		{"unchanged", "中文", "中文", CoverageStats{Han: 2}, 0},
+		{"no Chinese", "abc", "abc", CoverageStats{Other: 3}, 0},
+		{"empty", "", "", CoverageStats{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Coverage(tt.input, tt.output)
+			if got != tt.want {
+				t.Errorf("Coverage() = %+v, want %+v", got, tt.want)
+			}
+			if p := got.Percent(); p != tt.percent {
+				t.Errorf("Percent() = %v, want %v", p, tt.percent)
+			}
+		})
+	}
+}
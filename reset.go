@@ -0,0 +1,65 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Reset switches c to configFile without instantiating a new module. The
+// current configuration is closed before the new one is opened, so the
+// new dictionaries reuse the memory the old ones freed.
+//
+// configFile is checked before anything is closed, so an unknown
+// configuration leaves c as it was. If OpenCC fails to open configFile, c
+// reopens its previous configuration, in a fresh module instance if the
+// failure aborted the call with an exception; should that fail too, c is
+// left invalid, like an interrupted converter, until Recycle revives it
+// with the previous configuration. Resetting a closed converter returns
+// ErrInvalidConverter.
+func (c *Converter) Reset(configFile string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.invalid() {
+		return ErrInvalidConverter
+	}
+
+	o := *c.options
+	o.dataFS = c.data
+	root, err := mountConfig(&o, configFile)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := c.closeHandle(ctx); err != nil {
+		return fmt.Errorf("reset: %w", err)
+	}
+
+	prev := c.mount.fs
+	c.mount.fs = root
+	handle, err := c.mod.open(ctx, configFile)
+	if err != nil {
+		c.mount.fs = prev
+		// Only OpenCC's own failures leave the module fit to reopen, and
+		// not if they trapped, like an exception does
+		var convErr *ConversionError
+		switch {
+		case c.mod.trapped:
+			if rerr := c.recycle(ctx); rerr != nil {
+				getLogger().Warn("error recycling converter after a trap", "config", c.config, "error", rerr)
+			}
+		case errors.As(err, &convErr):
+			if prevHandle, reopenErr := c.mod.open(ctx, c.config); reopenErr == nil {
+				c.handle = prevHandle
+			} else {
+				getLogger().Warn("error reopening configuration", "config", c.config, "error", reopenErr)
+			}
+		}
+		return fmt.Errorf("reset: %w", err)
+	}
+
+	c.handle, c.config, c.conversions = handle, configFile, 0
+	return nil
+}
@@ -0,0 +1,106 @@
+package opencc
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReset(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	steps := []struct {
+		config string
+		input  string
+		want   string
+	}{
+		{"t2s.json", "繁體字", "繁体字"},
+		{"s2twp.json", "软件", "軟體"},
+		{"s2t.json", "简体字", "簡體字"},
+	}
+
+	for _, step := range steps {
+		if !bundled(t, step.config) {
+			continue
+		}
+		if err := converter.Reset(step.config); err != nil {
+			t.Fatalf("Reset(%q) error = %v", step.config, err)
+		}
+		if got, err := converter.Convert(step.input); err != nil || got != step.want {
+			t.Errorf("after Reset(%q): Convert(%q) = %q, %v, want %q", step.config, step.input, got, err, step.want)
+		}
+	}
+
+	// Recycling and cloning keep the new configuration
+	if err := converter.Reset("t2s.json"); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if err := converter.Recycle(); err != nil {
+		t.Fatalf("Recycle() error = %v", err)
+	}
+	clone, err := converter.Clone()
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	defer clone.Close()
+	for _, c := range []*Converter{converter, clone} {
+		if got, err := c.Convert("繁體字"); err != nil || got != "繁体字" {
+			t.Errorf("Convert() = %q, %v, want %q", got, err, "繁体字")
+		}
+	}
+}
+
+func TestResetFailure(t *testing.T) {
+	root, err := dataSubFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := []byte(`{"conversion_chain": [{"dict": {"type": "ocd2", "file": "missing.ocd2"}}]}`)
+	data := overlayFS{files: fstest.MapFS{"broken.json": &fstest.MapFile{Data: broken}}, base: root}
+
+	converter, err := NewConverter("s2t.json", WithDataFS(data))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		config  string
+		wantErr error
+	}{
+		{"missing.json", ErrConfigNotFound},
+		{"broken.json", ErrOpenFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			if err := converter.Reset(tt.config); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Reset(%q) error = %v, want %v", tt.config, err, tt.wantErr)
+			}
+			// The previous configuration is still in use
+			if got, err := converter.Convert("简体字"); err != nil || got != "簡體字" {
+				t.Errorf("Convert() = %q, %v, want %q", got, err, "簡體字")
+			}
+			// An exception from OpenCC doesn't leave a trapped module behind
+			if converter.mod.trapped {
+				t.Error("Reset() failure left the converter on a trapped module")
+			}
+		})
+	}
+}
+
+func TestResetClosed(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	converter.Close()
+
+	if err := converter.Reset("t2s.json"); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("Reset() error = %v, want %v", err, ErrInvalidConverter)
+	}
+}
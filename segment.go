@@ -0,0 +1,299 @@
+package opencc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Segment returns the boundaries of the segments OpenCC splits input into
+// before converting it, as rune offsets into input: segment i spans runes
+// bounds[i] to bounds[i+1], the first offset is 0 and the last is the number
+// of runes in input. Empty input has no segments and returns nil.
+//
+// OpenCC's dictionary-based segmentation matches the longest entry of the
+// configuration's segmentation dictionary at each position, and keeps the
+// text between matches together as one segment. The embedded opencc.wasm
+// only segments as part of a conversion, so Segment recovers the segments
+// by converting input with configurations derived from c's, in a module
+// instance of its own. It reloads the segmentation dictionary on every
+// call and is much slower than Convert.
+//
+// input is segmented as it is, without the byte order mark handling and
+// normalization of Convert, so the offsets always refer to input.
+func (c *Converter) Segment(input string) ([]int, error) {
+	c.mu.Lock()
+	if c.invalid() {
+		c.mu.Unlock()
+		return nil, ErrInvalidConverter
+	}
+	err := c.checkInput(input)
+	if err == nil {
+		// Rune offsets need valid UTF-8 even with WithSkipValidation
+		err = checkInput(input, true)
+	}
+	o := *c.options
+	base, configFile := c.mount.fs, c.config
+	c.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	if input == "" {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	s, err := newSegmenter(ctx, &o, base, configFile)
+	if err != nil {
+		return nil, fmt.Errorf("segment: %w", err)
+	}
+	defer s.close()
+
+	bounds, err := s.segment(ctx, []rune(input))
+	if err != nil {
+		return nil, fmt.Errorf("segment: %w", err)
+	}
+	return bounds, nil
+}
+
+// segmentMaxWord is the length in runes of the longest dictionary entry
+// segmenter.words looks for at first. It doubles until no entry is longer.
+const segmentMaxWord = 16
+
+// segmenter finds segments with configurations generated from a
+// converter's. Every segment of a conversion is converted separately, so a
+// conversion dictionary whose entries mark each match they make shows
+// where segments start.
+type segmenter struct {
+	*genModule
+	seg map[string]any // the converter's segmentation
+
+	// mark starts each match of a generated conversion dictionary, and
+	// probe never matches the segmentation dictionary. Neither occurs in
+	// the input.
+	mark, probe rune
+}
+
+// newSegmenter reads configFile's segmentation from base and instantiates a
+// module for the generated configurations.
+func newSegmenter(ctx context.Context, o *options, base fs.FS, configFile string) (*segmenter, error) {
+	name := path.Clean(strings.TrimPrefix(configFile, "/"))
+	data, err := fs.ReadFile(base, name)
+	if err != nil {
+		return nil, &ConversionError{Op: "open", Config: configFile, Err: ErrConfigNotFound}
+	}
+	var config struct {
+		Segmentation map[string]any `json:"segmentation"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", configFile, err)
+	}
+	if config.Segmentation["dict"] == nil {
+		return nil, fmt.Errorf("%s: no segmentation dictionary", configFile)
+	}
+
+	m, err := newGenModule(ctx, o, base, configFile)
+	if err != nil {
+		return nil, err
+	}
+	return &segmenter{genModule: m, seg: config.Segmentation}, nil
+}
+
+// segment returns the segment boundaries of text. Segments are what words
+// finds, except that adjacent characters the segmentation dictionary
+// matches nothing at are one segment, as OpenCC buffers them until the
+// next match.
+func (s *segmenter) segment(ctx context.Context, text []rune) ([]int, error) {
+	s.mark, s.probe = unusedRunes(text)
+
+	words, err := s.words(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only a single character can be one the dictionary doesn't match at
+	var candidates []rune
+	seen := make(map[rune]bool)
+	for i := 1; i < len(words); i++ {
+		if r := text[words[i-1]]; words[i]-words[i-1] == 1 && dictRune(r) && !seen[r] {
+			seen[r] = true
+			candidates = append(candidates, r)
+		}
+	}
+	keys, err := s.keys(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	unmatched := func(start, end int) bool {
+		if !dictRune(text[start]) {
+			return true
+		}
+		return end-start == 1 && !keys[text[start]]
+	}
+	bounds := []int{0}
+	for i := 1; i < len(words)-1; i++ {
+		if !unmatched(words[i-1], words[i]) || !unmatched(words[i], words[i+1]) {
+			bounds = append(bounds, words[i])
+		}
+	}
+	return append(bounds, len(text)), nil
+}
+
+// words returns the boundaries of text segmented with the segmentation
+// dictionary followed by one that matches every character on its own, so
+// that each character between dictionary matches is a segment by itself.
+// Characters that can't be dictionary entries, such as white space, match
+// neither, and each run of them is one segment.
+func (s *segmenter) words(ctx context.Context, text []rune) ([]int, error) {
+	chars := make(map[string]string)
+	for _, r := range text {
+		if dictRune(r) {
+			chars[string(r)] = string(r)
+		}
+	}
+	charsFile := s.addDict(chars)
+	seg := map[string]any{
+		"type":  "group",
+		"dicts": []any{s.seg["dict"], map[string]any{"type": "text", "file": charsFile}},
+	}
+
+	// Every run of text up to longest runes is an entry, so each segment is
+	// matched as a whole unless it is longer
+	for longest := segmentMaxWord; ; longest *= 2 {
+		dict := make(map[string]string)
+		for i := range text {
+			for j := i + 1; j <= len(text) && j-i <= longest && dictRune(text[j-1]); j++ {
+				word := string(text[i:j])
+				dict[word] = string(s.mark) + word
+			}
+		}
+		out, err := s.convertWith(ctx, seg, dict, string(text))
+		if err != nil {
+			return nil, err
+		}
+
+		bounds, long, err := s.marked(text, out, longest)
+		if err != nil {
+			return nil, err
+		}
+		if !long {
+			return bounds, nil
+		}
+	}
+}
+
+// marked returns the boundaries of text that out, text converted by words,
+// marks, along with whether a segment is longest runes long, which a longer
+// one might have been cut to.
+func (s *segmenter) marked(text []rune, out string, longest int) ([]int, bool, error) {
+	starts := make([]bool, len(text)+1)
+	i := 0
+	for _, r := range out {
+		if r == s.mark {
+			starts[i] = true
+			continue
+		}
+		if i >= len(text) || r != text[i] {
+			return nil, false, fmt.Errorf("unexpected conversion %q", out)
+		}
+		i++
+	}
+	if i != len(text) {
+		return nil, false, fmt.Errorf("unexpected conversion %q", out)
+	}
+
+	bounds := []int{0}
+	long := false
+	for i := 1; i <= len(text); i++ {
+		if i < len(text) && !starts[i] && dictRune(text[i]) == dictRune(text[i-1]) {
+			continue
+		}
+		start := bounds[len(bounds)-1]
+		if dictRune(text[start]) && i-start == longest {
+			long = true
+		}
+		bounds = append(bounds, i)
+	}
+	return bounds, long, nil
+}
+
+// keys reports which of chars the segmentation dictionary has an entry for.
+// Each character follows s.probe, which the dictionary doesn't match, so
+// it is only a segment of its own if it is an entry.
+func (s *segmenter) keys(ctx context.Context, chars []rune) (map[rune]bool, error) {
+	keys := make(map[rune]bool)
+	if len(chars) == 0 {
+		return keys, nil
+	}
+
+	dict := make(map[string]string)
+	var input strings.Builder
+	for _, r := range chars {
+		pair := string([]rune{s.probe, r})
+		dict[pair] = string(s.mark) + pair
+		input.WriteString(pair)
+	}
+	out, err := s.convertWith(ctx, s.seg["dict"], dict, input.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// A pair is only matched, and marked, within one segment
+	rest := []rune(out)
+	for _, r := range chars {
+		marked := len(rest) > 0 && rest[0] == s.mark
+		if marked {
+			rest = rest[1:]
+		}
+		if len(rest) < 2 || rest[0] != s.probe || rest[1] != r {
+			return nil, fmt.Errorf("unexpected conversion %q", out)
+		}
+		keys[r] = !marked
+		rest = rest[2:]
+	}
+	return keys, nil
+}
+
+// convertWith converts input with a configuration that segments with the
+// dictionary seg and converts by the entries of dict.
+func (s *segmenter) convertWith(ctx context.Context, seg any, dict map[string]string, input string) (string, error) {
+	segmentation := make(map[string]any)
+	for k, v := range s.seg {
+		segmentation[k] = v
+	}
+	segmentation["dict"] = seg
+	return s.convert(ctx, map[string]any{
+		"name":         "segment",
+		"segmentation": segmentation,
+		"conversion_chain": []any{
+			map[string]any{"dict": map[string]any{"type": "text", "file": s.addDict(dict)}},
+		},
+	}, input)
+}
+
+// dictRune reports whether r can be part of a text dictionary entry, which
+// white space and control characters can't.
+func dictRune(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsControl(r)
+}
+
+// unusedRunes returns two private use characters that don't occur in text.
+func unusedRunes(text []rune) (rune, rune) {
+	used := make(map[rune]bool)
+	for _, r := range text {
+		used[r] = true
+	}
+	var found []rune
+	for r := rune(0xE000); len(found) < 2; r++ {
+		if !used[r] {
+			found = append(found, r)
+		}
+	}
+	return found[0], found[1]
+}
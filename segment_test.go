@@ -0,0 +1,116 @@
+package opencc
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSegment(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	// s2t.json segments by STPhrases alone, which has 叹息 but none of the
+	// other words
+	tests := []struct {
+		input string
+		want  []int
+	}{
+		{"", nil},
+		{"叹息", []int{0, 2}},
+		{"虚伪叹息", []int{0, 2, 4}},
+		{"他虚伪地叹息了", []int{0, 4, 6, 7}},
+		{"我们的汉字很简单", []int{0, 8}},
+		{"abc 虚伪\n叹息 ", []int{0, 7, 9, 10}},
+	}
+	for _, tt := range tests {
+		got, err := converter.Segment(tt.input)
+		if err != nil {
+			t.Fatalf("Segment(%q) error = %v", tt.input, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Segment(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSegmentCharacters(t *testing.T) {
+	// Segmenting by STCharacters too, each character it has is a segment of
+	// its own, and only the ones it lacks are kept together
+	root, err := dataSubFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := overlayFS{base: root, files: fstest.MapFS{
+		"segment.json": {Data: []byte(`{
+			"segmentation": {"type": "mmseg", "dict": {"type": "group", "dicts": [
+				{"type": "ocd2", "file": "STPhrases.ocd2"},
+				{"type": "ocd2", "file": "STCharacters.ocd2"}
+			]}},
+			"conversion_chain": [{"dict": {"type": "ocd2", "file": "STCharacters.ocd2"}}]
+		}`)},
+	}}
+	converter, err := NewConverter("segment.json", WithDataFS(fsys))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	got, err := converter.Segment("我们的汉字很简单")
+	if err != nil {
+		t.Fatalf("Segment() error = %v", err)
+	}
+	if want := []int{0, 1, 2, 3, 4, 6, 7, 8}; !slices.Equal(got, want) {
+		t.Errorf("Segment() = %v, want %v", got, want)
+	}
+}
+
+func TestSegmentCustomDict(t *testing.T) {
+	// The custom entries are segmented like the built-in ones, however long
+	long := strings.Repeat("一二三四五六七八九十", 2)
+	dict := "汉字\t漢字\n" + long + "\t長\n"
+	converter, err := NewConverter("s2t.json", WithCustomDict(strings.NewReader(dict)))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		input string
+		want  []int
+	}{
+		{"我们的汉字很简单", []int{0, 3, 5, 8}},
+		{long + "虚伪", []int{0, 20, 22}},
+	}
+	for _, tt := range tests {
+		got, err := converter.Segment(tt.input)
+		if err != nil {
+			t.Fatalf("Segment(%q) error = %v", tt.input, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Segment(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSegmentInvalid(t *testing.T) {
+	converter, err := NewConverter("s2t.json", WithSkipValidation())
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+
+	var inputErr *InputError
+	if _, err := converter.Segment("虚伪\xff"); !errors.As(err, &inputErr) || inputErr.Offset != 6 {
+		t.Errorf("Segment() with invalid UTF-8 error = %v, want an InputError at offset 6", err)
+	}
+
+	converter.Close()
+	if _, err := converter.Segment("虚伪"); !errors.Is(err, ErrInvalidConverter) {
+		t.Errorf("Segment() after Close() error = %v, want %v", err, ErrInvalidConverter)
+	}
+}
@@ -0,0 +1,38 @@
+package opencc
+
+import (
+	"context"
+	"fmt"
+)
+
+// Sample conversion checked by SelfTest.
+const (
+	selfTestInput = "简体字"
+	selfTestWant  = "簡體字"
+)
+
+// SelfTest checks that the WASM runtime and the embedded dictionaries work
+// by converting a known sample. It uses the converters cached for
+// ConvertS2T, so only the first call pays for creating one, and later calls
+// are cheap enough for readiness probes. A converter whose runtime was shut
+// down is replaced, so the check still covers the runtime as it is now.
+func SelfTest(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("self-test: %w", err)
+	}
+
+	c, err := s2tPool.GetContext(ctx)
+	if err != nil {
+		return fmt.Errorf("self-test: %w", err)
+	}
+	defer s2tPool.Put(c)
+
+	got, err := c.ConvertContext(ctx, selfTestInput)
+	if err != nil {
+		return fmt.Errorf("self-test: %w", err)
+	}
+	if got != selfTestWant {
+		return fmt.Errorf("self-test: converted %q to %q, want %q", selfTestInput, got, selfTestWant)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(context.Background()); err != nil {
+		t.Errorf("SelfTest() error = %v", err)
+	}
+
+	// Later calls reuse the cached converter
+	created := defaultEngine.Stats().Created
+	if err := SelfTest(context.Background()); err != nil {
+		t.Errorf("SelfTest() error = %v", err)
+	}
+	if got := defaultEngine.Stats().Created; got != created {
+		t.Errorf("SelfTest() created %d converters, want 0", got-created)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := SelfTest(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("SelfTest() with canceled context error = %v, want %v", err, context.Canceled)
+	}
+
+	// The runtime is checked again after a shutdown
+	Shutdown(context.Background())
+	if err := SelfTest(context.Background()); err != nil {
+		t.Errorf("SelfTest() after Shutdown error = %v", err)
+	}
+}
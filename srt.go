@@ -0,0 +1,96 @@
+package opencc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// srtTimecode matches the timing line of a subtitle block, such as
+// "00:01:02,345 --> 00:01:04,000".
+var srtTimecode = regexp.MustCompile(`^\d+:\d{2}:\d{2}[,.]\d{3}\s*-->\s*\d+:\d{2}:\d{2}[,.]\d{3}`)
+
+// States of ConvertSRT while reading a subtitle block.
+const (
+	srtIndex     = iota // expecting the index line
+	srtTiming           // expecting the timing line
+	srtText             // converting text lines
+	srtMalformed        // copying a malformed block
+)
+
+// ConvertSRT converts the dialogue of SubRip (.srt) subtitles read from r
+// and writes them to w. Index numbers, timing lines, blank lines, line
+// endings and a byte order mark are written unchanged. Blocks that don't
+// start with an index and a timing line are copied without converting them.
+func ConvertSRT(c *Converter, r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	state := srtIndex
+
+	for first := true; ; first = false {
+		line, readErr := br.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("read: %w", readErr)
+		}
+		if line == "" {
+			break
+		}
+
+		if first && strings.HasPrefix(line, bom) {
+			bw.WriteString(bom)
+			line = line[len(bom):]
+		}
+
+		content := strings.TrimRight(line, "\r\n")
+		ending := line[len(content):]
+		blank := strings.TrimSpace(content) == ""
+
+		switch {
+		case blank:
+			state = srtIndex
+		case state == srtIndex:
+			state = srtMalformed
+			if isSRTIndex(strings.TrimSpace(content)) {
+				state = srtTiming
+			}
+		case state == srtTiming:
+			state = srtMalformed
+			if srtTimecode.MatchString(strings.TrimSpace(content)) {
+				state = srtText
+			}
+		case state == srtText:
+			converted, err := c.convertPart(content)
+			if err != nil {
+				return err
+			}
+			line = converted + ending
+		}
+
+		if _, err := bw.WriteString(line); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+// isSRTIndex reports whether s is a subtitle index number.
+func isSRTIndex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
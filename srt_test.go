@@ -0,0 +1,59 @@
+package opencc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConvertSRT(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "LF",
+			input: "1\n00:00:01,000 --> 00:00:02,500\n简体字幕\n第二行\n\n2\n00:00:03,000 --> 00:00:04,000\n汉字\n",
+			want:  "1\n00:00:01,000 --> 00:00:02,500\n簡體字幕\n第二行\n\n2\n00:00:03,000 --> 00:00:04,000\n漢字\n",
+		},
+		{
+			name:  "CRLFWithBOM",
+			input: "\ufeff1\r\n00:00:01,000 --> 00:00:02,500\r\n简体字幕\r\n\r\n",
+			want:  "\ufeff1\r\n00:00:01,000 --> 00:00:02,500\r\n簡體字幕\r\n\r\n",
+		},
+		{
+			name:  "NoTrailingNewline",
+			input: "1\n00:00:01,000 --> 00:00:02,500\n简体",
+			want:  "1\n00:00:01,000 --> 00:00:02,500\n簡體",
+		},
+		{
+			name:  "Malformed",
+			input: "简体注释\n没有时间\n\nx\n00:00:01,000 --> 00:00:02,500\n简体\n\n1\n简体\n\n2\n00:00:01,000 --> 00:00:02,500\n简体\n",
+			want:  "简体注释\n没有时间\n\nx\n00:00:01,000 --> 00:00:02,500\n简体\n\n1\n简体\n\n2\n00:00:01,000 --> 00:00:02,500\n簡體\n",
+		},
+		{
+			name:  "Empty",
+			input: "",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ConvertSRT(converter, strings.NewReader(tt.input), &buf); err != nil {
+				t.Fatalf("ConvertSRT() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("ConvertSRT() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package opencc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// StreamChunkSize is the number of bytes ConvertStream reads and converts
+// at a time.
+const StreamChunkSize = 64 * 1024
+
+// ConvertTo converts input and writes the result to w, returning the number
+// of bytes written. The result is written straight from WASM memory, so it
+// is never copied into a Go string. w is called with the converter locked
+// and must not use the converter itself.
+func (c *Converter) ConvertTo(w io.Writer, input string) (int, error) {
+	dest := &writerDest{w: w}
+	if err := c.convert(context.Background(), dest, input); err != nil {
+		return 0, err
+	}
+	if dest.err != nil {
+		return dest.n, fmt.Errorf("write: %w", dest.err)
+	}
+	return dest.n, nil
+}
+
+// ConvertStream reads text from r, converts it and writes the result to w.
+// The input is converted in chunks of up to StreamChunkSize bytes, so it
+// never has to be held in memory as a whole. Chunks end after the last
+// newline when there is one, so phrases within a line are converted
+// together, and otherwise on a rune boundary so multibyte characters are
+// never split between chunks.
+func (c *Converter) ConvertStream(r io.Reader, w io.Writer) error {
+	return c.ConvertStreamProgress(r, w, nil)
+}
+
+// ConvertStreamProgress is like ConvertStream, but calls progress after
+// each chunk with the total number of bytes read from r and written to w so
+// far. If progress returns an error, the conversion stops and the error is
+// returned. progress may be nil.
+func (c *Converter) ConvertStreamProgress(r io.Reader, w io.Writer, progress func(read, written int64) error) error {
+	if c.IsClosed() {
+		return ErrInvalidConverter
+	}
+
+	br := bufio.NewReaderSize(r, StreamChunkSize)
+	buf := make([]byte, StreamChunkSize)
+	carry := 0
+	offset := 0 // of buf in the input
+	var written int64
+
+	for {
+		n, err := io.ReadFull(br, buf[carry:])
+		n += carry
+		eof := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !eof {
+			return fmt.Errorf("read: %w", err)
+		}
+
+		// Flush everything, including a trailing partial rune, at EOF
+		cut := n
+		if !eof {
+			cut = chunkEnd(buf[:n])
+		}
+
+		if cut > 0 {
+			// Only the start of the stream holds a byte order mark
+			var result []byte
+			err := c.convertText(context.Background(), &result, buf[:cut], offset == 0)
+			if err != nil {
+				var inputErr *InputError
+				if errors.As(err, &inputErr) {
+					inputErr.Offset += offset
+				}
+				return err
+			}
+			n, err := w.Write(result)
+			written += int64(n)
+			if err != nil {
+				return fmt.Errorf("write: %w", err)
+			}
+		}
+
+		if progress != nil {
+			if err := progress(int64(offset+cut), written); err != nil {
+				return err
+			}
+		}
+
+		if eof {
+			return nil
+		}
+		carry = copy(buf, buf[cut:n])
+		offset += cut
+	}
+}
+
+// chunkEnd returns where to cut b so the cut doesn't fall inside a line,
+// or failing that, inside a rune.
+func chunkEnd(b []byte) int {
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		return i + 1
+	}
+
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if utf8.FullRune(b[i:]) {
+				return len(b)
+			}
+			if i > 0 {
+				return i
+			}
+			break
+		}
+	}
+	return len(b)
+}
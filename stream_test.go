@@ -0,0 +1,179 @@
+package opencc
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConvertStream(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"short", "这是一个测试"},
+		// 9-byte unit doesn't divide the chunk size, so chunks end mid-rune
+		{"no newlines", strings.Repeat("简体字", 1<<18/9)},
+		{"1MB document", strings.Repeat("这是一个测试，用来测试转换。\n", 1<<20/43)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want string
+			if tt.input != "" {
+				if want, err = converter.Convert(tt.input); err != nil {
+					t.Fatalf("Convert() error = %v", err)
+				}
+			}
+
+			var out bytes.Buffer
+			if err := converter.ConvertStream(strings.NewReader(tt.input), &out); err != nil {
+				t.Fatalf("ConvertStream() error = %v", err)
+			}
+			if out.String() != want {
+				t.Errorf("ConvertStream() output differs from Convert() (len %d, want %d)", out.Len(), len(want))
+			}
+		})
+	}
+}
+
+func TestChunkEnd(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"abc", 3},
+		{"ab\ncd", 3},
+		{"简体", 6},
+		{"简体"[:5], 3},
+		{"简体"[:4], 3},
+		{"\xe7", 1},
+		{"a𠀀", 5},
+		{"a𠀀"[:4], 1},
+		{"a𠀀"[:3], 1},
+		{"a𠀀"[:2], 1},
+	}
+
+	for _, tt := range tests {
+		if got := chunkEnd([]byte(tt.input)); got != tt.want {
+			t.Errorf("chunkEnd(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestConvertTo(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	var buf bytes.Buffer
+	n, err := converter.ConvertTo(&buf, "这是简体字")
+	if err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if want := "這是簡體字"; buf.String() != want || n != len(want) {
+		t.Errorf("ConvertTo() = %d, %q, want %d, %q", n, buf.String(), len(want), want)
+	}
+
+	if _, err := converter.ConvertTo(&buf, "简体\xff"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("ConvertTo() error = %v, want %v", err, ErrInvalidInput)
+	}
+
+	writeErr := errors.New("disk full")
+	if _, err := converter.ConvertTo(failingWriter{writeErr}, "简体字"); !errors.Is(err, writeErr) {
+		t.Errorf("ConvertTo() error = %v, want %v", err, writeErr)
+	}
+
+	// The converter stays usable after a failed write
+	buf.Reset()
+	if _, err := converter.ConvertTo(&buf, "简体字"); err != nil || buf.String() != "簡體字" {
+		t.Errorf("ConvertTo() = %q, %v, want %q", buf.String(), err, "簡體字")
+	}
+}
+
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestConvertStreamProgress(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	line := "这是一个简体字的测试。\n"
+	input := strings.Repeat(line, 3*StreamChunkSize/len(line))
+
+	var buf bytes.Buffer
+	var calls int
+	var lastRead, lastWritten int64
+	err = converter.ConvertStreamProgress(strings.NewReader(input), &buf, func(read, written int64) error {
+		calls++
+		if read < lastRead || written < lastWritten {
+			t.Errorf("progress went backwards: (%d, %d) after (%d, %d)", read, written, lastRead, lastWritten)
+		}
+		lastRead, lastWritten = read, written
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ConvertStreamProgress() error = %v", err)
+	}
+	if calls < 3 {
+		t.Errorf("progress called %d times, want at least 3", calls)
+	}
+	if lastRead != int64(len(input)) || lastWritten != int64(buf.Len()) {
+		t.Errorf("final progress = (%d, %d), want (%d, %d)", lastRead, lastWritten, len(input), buf.Len())
+	}
+
+	// Abort after the first chunk
+	errBudget := errors.New("budget exceeded")
+	buf.Reset()
+	err = converter.ConvertStreamProgress(strings.NewReader(input), &buf, func(read, written int64) error {
+		return errBudget
+	})
+	if !errors.Is(err, errBudget) {
+		t.Errorf("ConvertStreamProgress() error = %v, want %v", err, errBudget)
+	}
+	if buf.Len() == 0 || buf.Len() >= len(input) {
+		t.Errorf("wrote %d bytes before aborting, want only the first chunk", buf.Len())
+	}
+
+	// The converter stays usable after aborting
+	if result, err := converter.Convert("简体"); err != nil || result != "簡體" {
+		t.Errorf("Convert() after abort = %q, %v", result, err)
+	}
+}
+
+func TestConvertStreamSupplementaryPlane(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	// No newlines, and every offset modulo 4 in front of the 4-byte rune,
+	// so chunk boundaries fall inside it
+	for pad := 0; pad < 4; pad++ {
+		input := strings.Repeat("a", pad) + strings.Repeat("𠀀简", StreamChunkSize/7*3)
+		want := strings.Repeat("a", pad) + strings.Repeat("𠀀簡", StreamChunkSize/7*3)
+
+		var buf bytes.Buffer
+		if err := converter.ConvertStream(strings.NewReader(input), &buf); err != nil {
+			t.Fatalf("ConvertStream() error = %v", err)
+		}
+		if buf.String() != want {
+			t.Errorf("ConvertStream() with %d bytes of padding mangled the output", pad)
+		}
+	}
+}
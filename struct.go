@@ -0,0 +1,145 @@
+package opencc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ConvertStruct converts, in place, every string reachable from v through
+// exported struct fields, pointers, slices, arrays, maps and interfaces.
+// v must be a non-nil pointer, since only addressable strings can be
+// modified. Map values are replaced, but map keys are left alone, as are
+// unexported fields and fields tagged `opencc:"-"`. Named string types are
+// converted too. On failure, the error names the path to the string that
+// couldn't be converted, and strings visited before it stay converted.
+func ConvertStruct(c *Converter, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("convert struct: need a non-nil pointer")
+	}
+
+	w := structWalker{c: c, seen: make(map[visit]bool)}
+	return w.walk(rv, "")
+}
+
+// structWalker converts the strings ConvertStruct reaches.
+type structWalker struct {
+	c    *Converter
+	seen map[visit]bool // walked already, to stop at cycles
+}
+
+// visit identifies a pointer, slice or map walked. Slices sharing a backing
+// array differ in length, and a pointer to the first element of a slice in
+// type.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// visited reports whether the pointer, slice or map v was walked before,
+// and marks it walked.
+func (w *structWalker) visited(v reflect.Value) bool {
+	key := visit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	if w.seen[key] {
+		return true
+	}
+	w.seen[key] = true
+	return false
+}
+
+// walk converts the strings in v, which is settable unless it is a pointer,
+// map or slice whose elements are. path names v in errors.
+func (w *structWalker) walk(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() == 0 || !v.CanSet() {
+			return nil
+		}
+		result, err := w.c.convertPart(v.String())
+		if err != nil {
+			return fmt.Errorf("convert struct: %s: %w", pathOrRoot(path), err)
+		}
+		v.SetString(result)
+
+	case reflect.Pointer:
+		if v.IsNil() || w.visited(v) {
+			return nil
+		}
+		return w.walk(v.Elem(), path)
+
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return nil
+		}
+		// The dynamic value isn't addressable, so convert a copy and
+		// store it back
+		elem := v.Elem()
+		cp := reflect.New(elem.Type()).Elem()
+		cp.Set(elem)
+		if err := w.walk(cp, path); err != nil {
+			return err
+		}
+		v.Set(cp)
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() || field.Tag.Get("opencc") == "-" {
+				continue
+			}
+			if err := w.walk(v.Field(i), joinPath(path, field.Name)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice:
+		if v.IsNil() || w.visited(v) {
+			return nil
+		}
+		fallthrough
+
+	case reflect.Array:
+		for i := range v.Len() {
+			if err := w.walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		if v.IsNil() || w.visited(v) {
+			return nil
+		}
+
+		iter := v.MapRange()
+		for iter.Next() {
+			// Map values aren't addressable either
+			cp := reflect.New(v.Type().Elem()).Elem()
+			cp.Set(iter.Value())
+			if err := w.walk(cp, fmt.Sprintf("%s[%v]", path, iter.Key())); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), cp)
+		}
+	}
+	return nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "value"
+	}
+	return path
+}
@@ -0,0 +1,123 @@
+package opencc
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type article struct {
+	Title    string
+	Slug     string `opencc:"-"`
+	Tags     []string
+	Meta     map[string]string
+	Author   *author
+	Related  []author
+	Extra    any
+	Label    label
+	Count    int
+	internal string
+}
+
+type author struct {
+	Name string
+	Next *author
+}
+
+type label string
+
+func TestConvertStruct(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	a := &author{Name: "汉字"}
+	a.Next = a // cycle
+	v := article{
+		Title:    "简体字",
+		Slug:     "简体",
+		Tags:     []string{"汉字", ""},
+		Meta:     map[string]string{"简体": "汉字"},
+		Author:   a,
+		Related:  []author{{Name: "简体"}},
+		Extra:    map[string]any{"k": "汉字", "n": []any{"简体", 1}},
+		Label:    "简体",
+		Count:    1,
+		internal: "简体",
+	}
+
+	if err := ConvertStruct(converter, &v); err != nil {
+		t.Fatalf("ConvertStruct() error = %v", err)
+	}
+
+	want := article{
+		Title:    "簡體字",
+		Slug:     "简体",
+		Tags:     []string{"漢字", ""},
+		Meta:     map[string]string{"简体": "漢字"},
+		Author:   a,
+		Related:  []author{{Name: "簡體"}},
+		Extra:    map[string]any{"k": "漢字", "n": []any{"簡體", 1}},
+		Label:    "簡體",
+		Count:    1,
+		internal: "简体",
+	}
+	if a.Name != "漢字" {
+		t.Errorf("Author.Name = %q, want %q", a.Name, "漢字")
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("ConvertStruct() = %+v, want %+v", v, want)
+	}
+}
+
+func TestConvertStructSharedSlice(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	// Slices sharing a backing array are each walked in full
+	all := []string{"简体", "汉字"}
+	v := struct{ First, All []string }{all[:1], all}
+	if err := ConvertStruct(converter, &v); err != nil {
+		t.Fatalf("ConvertStruct() error = %v", err)
+	}
+	if want := []string{"簡體", "漢字"}; !reflect.DeepEqual(v.All, want) {
+		t.Errorf("All = %q, want %q", v.All, want)
+	}
+
+	// A slice holding itself is still walked only once
+	self := []any{"简体", nil}
+	self[1] = self
+	if err := ConvertStruct(converter, &self); err != nil {
+		t.Fatalf("ConvertStruct() error = %v", err)
+	}
+	if self[0] != "簡體" {
+		t.Errorf("self[0] = %q, want %q", self[0], "簡體")
+	}
+}
+
+func TestConvertStructErrors(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	var nilPtr *article
+	for _, v := range []any{article{}, nilPtr, nil} {
+		if err := ConvertStruct(converter, v); err == nil {
+			t.Errorf("ConvertStruct(%T) error = nil, want error", v)
+		}
+	}
+
+	bad := article{Related: []author{{Name: "ok"}, {Name: "a\x00b"}}}
+	err = ConvertStruct(converter, &bad)
+	if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "Related[1].Name") {
+		t.Errorf("ConvertStruct() error = %v, want %v naming Related[1].Name", err, ErrInvalidInput)
+	}
+}
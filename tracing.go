@@ -0,0 +1,46 @@
+package opencc
+
+import "context"
+
+// Tracer starts a span for each conversion of a converter created with
+// WithTracer. It is the subset of OpenTelemetry's trace.Tracer the package
+// needs, so the package doesn't depend on OpenTelemetry; an adapter wrapping
+// a trace.Tracer and trace.Span takes a few lines.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetAttribute records an attribute, with a string or int value
+	SetAttribute(key string, value any)
+	// RecordError records that the conversion failed with err
+	RecordError(err error)
+	// End ends the span
+	End()
+}
+
+// Span attributes recorded for each conversion.
+const (
+	AttrConfig       = "opencc.config"
+	AttrInputLength  = "opencc.input_length"
+	AttrOutputLength = "opencc.output_length"
+)
+
+// startSpan starts a span for converting input with c, returning the
+// context to convert with and a function ending the span once the result
+// is stored in dest.
+func (c *Converter) startSpan(ctx context.Context, input any) (context.Context, func(dest any, err error)) {
+	ctx, span := c.tracer.Start(ctx, "opencc.Convert")
+	span.SetAttribute(AttrConfig, c.config)
+	span.SetAttribute(AttrInputLength, inputLen(input))
+
+	return ctx, func(dest any, err error) {
+		if err != nil {
+			span.RecordError(err)
+		} else {
+			span.SetAttribute(AttrOutputLength, outputLen(dest))
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,76 @@
+package opencc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	name   string
+	parent any
+	attrs  map[string]any
+	err    error
+	ended  bool
+}
+
+type parentKey struct{}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span := &recordingSpan{name: name, parent: ctx.Value(parentKey{}), attrs: make(map[string]any)}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (s *recordingSpan) SetAttribute(key string, value any) { s.attrs[key] = value }
+func (s *recordingSpan) RecordError(err error)              { s.err = err }
+func (s *recordingSpan) End()                               { s.ended = true }
+
+func TestWithTracer(t *testing.T) {
+	tracer := new(recordingTracer)
+	converter, err := NewConverter("s2t.json", WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	ctx := context.WithValue(context.Background(), parentKey{}, "parent")
+	if _, err := converter.ConvertContext(ctx, "汉字"); err != nil {
+		t.Fatalf("ConvertContext() error = %v", err)
+	}
+	if _, err := converter.Convert("a\x00b"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Convert() error = %v, want %v", err, ErrInvalidInput)
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("started %d spans, want 2", len(tracer.spans))
+	}
+
+	ok := tracer.spans[0]
+	if ok.name != "opencc.Convert" || ok.parent != "parent" || !ok.ended || ok.err != nil {
+		t.Errorf("span = %+v, want an ended opencc.Convert span under the parent context", ok)
+	}
+	wantAttrs := map[string]any{AttrConfig: "s2t.json", AttrInputLength: 6, AttrOutputLength: 6}
+	for key, want := range wantAttrs {
+		if got := ok.attrs[key]; got != want {
+			t.Errorf("attribute %s = %v, want %v", key, got, want)
+		}
+	}
+
+	failed := tracer.spans[1]
+	if !errors.Is(failed.err, ErrInvalidInput) || !failed.ended {
+		t.Errorf("failed span = %+v, want an ended span recording %v", failed, ErrInvalidInput)
+	}
+	if _, ok := failed.attrs[AttrOutputLength]; ok {
+		t.Errorf("failed span has attribute %s", AttrOutputLength)
+	}
+}
@@ -0,0 +1,97 @@
+package opencc
+
+import (
+	"context"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// Transformer adapts a Converter to transform.Transformer so it can be
+// used with transform.NewReader, transform.NewWriter and transform.Chain.
+type Transformer struct {
+	c *Converter
+
+	// started is set once input has been consumed. Only the start of a
+	// stream holds a byte order mark; elsewhere U+FEFF is a zero width
+	// no-break space, which must be kept.
+	started bool
+}
+
+var _ transform.Transformer = (*Transformer)(nil)
+
+// NewTransformer returns a Transformer converting text with c.
+func NewTransformer(c *Converter) *Transformer {
+	return &Transformer{c: c}
+}
+
+// NewConvertingReader returns a reader that reads from r and yields the
+// text converted with c. Input is converted a line at a time where
+// possible, and multibyte characters split between reads are held back
+// until they are complete; anything left at EOF is converted and returned
+// before io.EOF.
+func NewConvertingReader(c *Converter, r io.Reader) io.Reader {
+	return transform.NewReader(r, NewTransformer(c))
+}
+
+// Reset implements transform.Resetter, readying t for a new stream.
+func (t *Transformer) Reset() {
+	t.started = false
+}
+
+// Transform implements transform.Transformer. Unless atEOF is set, src is
+// only converted up to its last newline, or failing that its last complete
+// rune, and transform.ErrShortSrc is returned to ask for the rest.
+func (t *Transformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if t.c.IsClosed() {
+		return 0, 0, ErrInvalidConverter
+	}
+	if len(src) == 0 {
+		return 0, 0, nil
+	}
+	defer func() {
+		t.started = t.started || nSrc > 0
+	}()
+
+	cut := len(src)
+	if !atEOF {
+		cut = chunkEnd(src)
+		if cut == len(src) && !utf8.FullRune(src[runeStart(src, cut-1):]) {
+			// src holds nothing but the start of a rune
+			return 0, 0, transform.ErrShortSrc
+		}
+		if cut < len(src) {
+			err = transform.ErrShortSrc
+		}
+	}
+
+	// Convert less at a time when the result doesn't fit in dst
+	for cut > 0 {
+		var out []byte
+		convErr := t.c.convertText(context.Background(), &out, src[:cut], !t.started)
+		if convErr != nil {
+			return 0, 0, convErr
+		}
+		if len(out) <= len(dst) {
+			return copy(dst, out), cut, err
+		}
+		cut = runeStart(src, cut/2)
+		err = transform.ErrShortDst
+	}
+	return 0, 0, transform.ErrShortDst
+}
+
+// runeStart returns the start of the rune containing b[i], or i if b[i] is
+// the start of a rune or the end of b.
+func runeStart(b []byte, i int) int {
+	if i >= len(b) {
+		return i
+	}
+	for j := i; j >= 0 && j > i-utf8.UTFMax; j-- {
+		if utf8.RuneStart(b[j]) {
+			return j
+		}
+	}
+	return i
+}
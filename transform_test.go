@@ -0,0 +1,170 @@
+package opencc
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"golang.org/x/text/transform"
+)
+
+func TestTransformer(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tr := NewTransformer(converter)
+
+	result, _, err := transform.String(tr, "这是一个测试\n简体字")
+	if err != nil {
+		t.Fatalf("transform.String() error = %v", err)
+	}
+	if want := "這是一個測試\n簡體字"; result != want {
+		t.Errorf("transform.String() = %q, want %q", result, want)
+	}
+
+	// Feed one byte at a time so every rune arrives split
+	r := transform.NewReader(iotest.OneByteReader(strings.NewReader("简体字\n转换")), tr)
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := "簡體字\n轉換"; string(out) != want {
+		t.Errorf("ReadAll() = %q, want %q", out, want)
+	}
+}
+
+func TestTransformerShortSrc(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tr := NewTransformer(converter)
+	dst := make([]byte, 64)
+
+	src := []byte("简体")
+	nDst, nSrc, err := tr.Transform(dst, src[:5], false)
+	if err != transform.ErrShortSrc {
+		t.Fatalf("Transform() error = %v, want %v", err, transform.ErrShortSrc)
+	}
+	if string(dst[:nDst]) != "簡" || nSrc != 3 {
+		t.Errorf("Transform() = %q, %d, want %q, %d", dst[:nDst], nSrc, "簡", 3)
+	}
+
+	if _, _, err := tr.Transform(dst, src[3:5], false); err != transform.ErrShortSrc {
+		t.Errorf("Transform() on partial rune error = %v, want %v", err, transform.ErrShortSrc)
+	}
+}
+
+func TestTransformerShortDst(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tr := NewTransformer(converter)
+	dst := make([]byte, 4)
+
+	nDst, nSrc, err := tr.Transform(dst, []byte("简体字"), true)
+	if err != transform.ErrShortDst {
+		t.Fatalf("Transform() error = %v, want %v", err, transform.ErrShortDst)
+	}
+	if string(dst[:nDst]) != "簡" || nSrc != 3 {
+		t.Errorf("Transform() = %q, %d, want %q, %d", dst[:nDst], nSrc, "簡", 3)
+	}
+}
+
+func TestTransformerSupplementaryPlane(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	// Feed one byte at a time so every 4-byte rune arrives split
+	r := transform.NewReader(iotest.OneByteReader(strings.NewReader("𠀀简体𪚥")), NewTransformer(converter))
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := "𠀀簡體𪚥"; string(out) != want {
+		t.Errorf("ReadAll() = %q, want %q", out, want)
+	}
+}
+
+func TestTransformerBOM(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	// Only a byte order mark at the start of the stream is stripped, however
+	// the input is split into reads
+	input := "\ufeff简体\n\ufeff字\n"
+	want := "簡體\n\ufeff字\n"
+	got, err := io.ReadAll(NewConvertingReader(converter, iotest.OneByteReader(strings.NewReader(input))))
+	if err != nil || string(got) != want {
+		t.Errorf("NewConvertingReader() read %q, %v, want %q", got, err, want)
+	}
+
+	// Reset starts a new stream
+	tr := NewTransformer(converter)
+	for _, tt := range []struct{ input, want string }{
+		{"简体\n", "簡體\n"},
+		{"\ufeff字", "字"},
+	} {
+		if got, _, err := transform.String(tr, tt.input); err != nil || got != tt.want {
+			t.Errorf("transform.String(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestConvertingReader(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	input := strings.Repeat("这是一个测试\n", 10000) + "简体字"
+	want := strings.Repeat("這是一個測試\n", 10000) + "簡體字"
+
+	short := strings.Repeat("这是一个测试\n", 100) + "简体字"
+	shortWant := strings.Repeat("這是一個測試\n", 100) + "簡體字"
+
+	tests := []struct {
+		name string
+		r    io.Reader
+		want string
+	}{
+		{"Whole", strings.NewReader(input), want},
+		{"Half", iotest.HalfReader(strings.NewReader(input)), want},
+		{"OneByte", iotest.OneByteReader(strings.NewReader(short)), shortWant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := io.ReadAll(NewConvertingReader(converter, tt.r))
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("ReadAll() returned %d bytes, want %d", len(out), len(tt.want))
+			}
+		})
+	}
+
+	// Errors from the underlying reader are passed on
+	errRead := errors.New("read failed")
+	_, err = io.ReadAll(NewConvertingReader(converter, iotest.ErrReader(errRead)))
+	if !errors.Is(err, errRead) {
+		t.Errorf("ReadAll() error = %v, want %v", err, errRead)
+	}
+}
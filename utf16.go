@@ -0,0 +1,62 @@
+package opencc
+
+import (
+	"errors"
+	"unicode/utf16"
+)
+
+// ConvertUTF16 converts UTF-16 encoded input, such as text from Windows
+// APIs or Java, and returns the result as UTF-16. Surrogate pairs are
+// decoded, so characters outside the Basic Multilingual Plane convert like
+// any other. Unpaired surrogates, NUL characters and other invalid input are
+// reported as an *InputError whose Offset counts uint16 code units. The
+// limit set with WithMaxInputSize applies to the input encoded as UTF-8.
+func (c *Converter) ConvertUTF16(input []uint16) ([]uint16, error) {
+	if c.IsClosed() {
+		return nil, ErrInvalidConverter
+	}
+	if i := unpairedSurrogate(input); i >= 0 {
+		return nil, &InputError{Offset: i, Reason: "unpaired UTF-16 surrogate"}
+	}
+
+	text := string(utf16.Decode(input))
+	result, err := c.Convert(text)
+	if err != nil {
+		var inputErr *InputError
+		if errors.As(err, &inputErr) {
+			return nil, &InputError{Offset: utf16Offset(text, inputErr.Offset), Reason: inputErr.Reason}
+		}
+		return nil, err
+	}
+	return utf16.Encode([]rune(result)), nil
+}
+
+// unpairedSurrogate returns the index of the first surrogate in s that
+// isn't part of a valid pair, or -1 if there is none.
+func unpairedSurrogate(s []uint16) int {
+	for i := 0; i < len(s); i++ {
+		if !utf16.IsSurrogate(rune(s[i])) {
+			continue
+		}
+		if i+1 < len(s) && utf16.DecodeRune(rune(s[i]), rune(s[i+1])) != 0xfffd {
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// utf16Offset returns the number of UTF-16 code units encoding s up to the
+// byte offset n.
+func utf16Offset(s string, n int) int {
+	units := 0
+	for _, r := range s[:n] {
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+	}
+	return units
+}
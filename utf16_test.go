@@ -0,0 +1,60 @@
+package opencc
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestConvertUTF16(t *testing.T) {
+	converter, err := NewConverter("s2t.json")
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"BMP", "简体字", "簡體字"},
+		{"SurrogatePairs", "𠀀简体𪚥字", "𠀀簡體𪚥字"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := converter.ConvertUTF16(utf16.Encode([]rune(tt.input)))
+			if err != nil {
+				t.Fatalf("ConvertUTF16() error = %v", err)
+			}
+			if want := utf16.Encode([]rune(tt.want)); !slices.Equal(got, want) {
+				t.Errorf("ConvertUTF16() = %q, want %q", string(utf16.Decode(got)), tt.want)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name   string
+		input  []uint16
+		offset int
+	}{
+		{"LoneHigh", []uint16{0x7b80, 0xd840}, 1},
+		{"LoneLow", []uint16{0xdc00, 0x7b80}, 0},
+		{"HighHigh", []uint16{0x7b80, 0xd840, 0xd840, 0xdc00}, 1},
+		{"NUL", []uint16{0x7b80, 0x4f53, 0, 0x5b57}, 2},
+		{"NULAfterPair", []uint16{0xd840, 0xdc00, 0, 0x5b57}, 2},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.ConvertUTF16(tt.input)
+			var inputErr *InputError
+			if !errors.As(err, &inputErr) {
+				t.Fatalf("ConvertUTF16() error = %v, want *InputError", err)
+			}
+			if inputErr.Offset != tt.offset {
+				t.Errorf("Offset = %d, want %d", inputErr.Offset, tt.offset)
+			}
+		})
+	}
+}
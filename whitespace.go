@@ -0,0 +1,130 @@
+package opencc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// preserved returns the predicate for the runes the options ask to keep
+// as they are, or nil if there are none.
+func (o *options) preserved() func(rune) bool {
+	switch {
+	case o.preserveWhitespace && o.preservePunctuation:
+		return func(r rune) bool { return unicode.IsSpace(r) || isPunctuation(r) }
+	case o.preserveWhitespace:
+		return unicode.IsSpace
+	case o.preservePunctuation:
+		return isPunctuation
+	}
+	return nil
+}
+
+// isPunctuation reports whether r is punctuation, a symbol or a full-width
+// or half-width form, but not a Chinese character.
+func isPunctuation(r rune) bool {
+	if unicode.Is(unicode.Han, r) {
+		return false
+	}
+	return unicode.IsPunct(r) || unicode.IsSymbol(r) ||
+		r >= 0x3000 && r <= 0x303F || // CJK Symbols and Punctuation
+		r >= 0xFF00 && r <= 0xFFEF // Halfwidth and Fullwidth Forms
+}
+
+// convertPreserving converts input like convertLocked, but makes sure the
+// result keeps every rune of input matched by c.preserve where it was.
+// c.mu must be held.
+func (c *Converter) convertPreserving(ctx context.Context, dest, input any) error {
+	var in string
+	switch v := input.(type) {
+	case string:
+		in = v
+	case []byte:
+		in = string(v)
+	default:
+		return fmt.Errorf("unsupported input type: %T", input)
+	}
+
+	var out string
+	if err := c.convertLocked(ctx, &out, in); err != nil {
+		return err
+	}
+	if out != "" && !sameRuns(in, out, c.preserve) {
+		var err error
+		if out, err = c.convertFields(ctx, in); err != nil {
+			return err
+		}
+	}
+
+	switch d := dest.(type) {
+	case *string:
+		*d = out
+	case *[]byte:
+		*d = []byte(out)
+	case *writerDest:
+		n, err := io.WriteString(d.w, out)
+		d.n += n
+		d.err = err
+	default:
+		return fmt.Errorf("unsupported destination type: %T", dest)
+	}
+	return nil
+}
+
+// convertFields converts the text between the runs of preserved runes in s
+// one piece at a time, copying the preserved runes themselves. c.mu must be
+// held.
+func (c *Converter) convertFields(ctx context.Context, s string) (string, error) {
+	var sb strings.Builder
+	for s != "" {
+		n := keptLen(s, c.preserve)
+		if n == 0 {
+			n = textLen(s, c.preserve)
+			var field string
+			if err := c.convertLocked(ctx, &field, s[:n]); err != nil {
+				return "", err
+			}
+			sb.WriteString(field)
+		} else {
+			sb.WriteString(s[:n])
+		}
+		s = s[n:]
+	}
+	return sb.String(), nil
+}
+
+// sameRuns reports whether a and b have the same runs of runes matched by
+// keep, with text between the same runs.
+func sameRuns(a, b string, keep func(rune) bool) bool {
+	for a != "" && b != "" {
+		na, nb := keptLen(a, keep), keptLen(b, keep)
+		if na != nb || a[:na] != b[:nb] {
+			return false
+		}
+		if na == 0 {
+			// Skip the text up to the next kept rune
+			na, nb = textLen(a, keep), textLen(b, keep)
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return a == "" && b == ""
+}
+
+// keptLen returns the length of the runes matched by keep at the start of s.
+func keptLen(s string, keep func(rune) bool) int {
+	if i := strings.IndexFunc(s, func(r rune) bool { return !keep(r) }); i >= 0 {
+		return i
+	}
+	return len(s)
+}
+
+// textLen returns the length of the text before the first rune matched by
+// keep in s.
+func textLen(s string, keep func(rune) bool) int {
+	if i := strings.IndexFunc(s, keep); i >= 0 {
+		return i
+	}
+	return len(s)
+}
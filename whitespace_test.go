@@ -0,0 +1,111 @@
+package opencc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestWithPreserveWhitespace(t *testing.T) {
+	converter, err := NewConverter("s2t.json", WithPreserveWhitespace())
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	defer converter.Close()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"spaces only", "   ", "   "},
+		{"newline only", "\n", "\n"},
+		{"mixed line endings", "简体字\r\n汉字\n\r\n测试", "簡體字\r\n漢字\n\r\n測試"},
+		{"trailing spaces", "简体字  \t\n汉字 ", "簡體字  \t\n漢字 "},
+		{"leading whitespace", "\n\n  简体字", "\n\n  簡體字"},
+		{"ideographic space", "简体字　汉字", "簡體字　漢字"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := converter.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Convert() = %q, want %q", got, tt.want)
+			}
+
+			var out bytes.Buffer
+			if err := converter.ConvertStream(strings.NewReader(tt.input), &out); err != nil {
+				t.Fatalf("ConvertStream() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("ConvertStream() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSameWhitespace(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"", "", true},
+		{"简体字", "簡體字", true},
+		{"简 体\r\n字", "簡 體\r\n字", true},
+		{"简 体", "簡體", false},
+		{"简\r\n体", "簡\n體", false},
+		{"简体 ", "簡體", false},
+		{" 简体", "簡體", false},
+	}
+
+	for _, tt := range tests {
+		if got := sameRuns(tt.a, tt.b, unicode.IsSpace); got != tt.want {
+			t.Errorf("sameRuns(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestWithPreservePunctuation(t *testing.T) {
+	dict := "“\t「\n”\t」\n，\t,\n简体\t簡體\n"
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"converted", nil, "「簡體」,漢字"},
+		{"preserved", []Option{WithPreservePunctuation()}, "“簡體”，漢字"},
+		{"with whitespace", []Option{WithPreservePunctuation(), WithPreserveWhitespace()}, "“簡體”，漢字"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithCustomDict(strings.NewReader(dict))}, tt.opts...)
+			converter, err := NewConverter("s2t.json", opts...)
+			if err != nil {
+				t.Fatalf("NewConverter() error = %v", err)
+			}
+			defer converter.Close()
+
+			if got, err := converter.Convert("“简体”，汉字"); err != nil || got != tt.want {
+				t.Errorf("Convert() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPunctuation(t *testing.T) {
+	for _, r := range "“”「」，。！？,.!?()（）《》…—·、～ＡＢ１２｡" {
+		if !isPunctuation(r) {
+			t.Errorf("isPunctuation(%q) = false, want true", r)
+		}
+	}
+	for _, r := range "简體字〇々aZ09 \n" {
+		if isPunctuation(r) {
+			t.Errorf("isPunctuation(%q) = true, want false", r)
+		}
+	}
+}